@@ -8,13 +8,17 @@ type Card struct {
 	Title             string `csv:"title" json:"title"`
 	Subtitle          string `csv:"subtitle" json:"subtitle,omitempty"`
 	Type              string `csv:"type" json:"-"`
-	Trait             string `csv:"trait" json:"-"`
+	Traits            List   `csv:"trait" json:"-"`
 	MP                int    `csv:"mp" json:"mp"`
-	Symbol            string `csv:"symbol" json:"symbol"`
-	Effect            string `csv:"effect" json:"effect,omitempty"`
-	Strength          int    `csv:"strength" json:"-"`     // TODO: This are being defaulted as 0
-	Intelligence      int    `csv:"intelligence" json:"-"` // TODO: This are being defaulted as 0
-	Special           int    `csv:"special" json:"-"`      // TODO: This are being defaulted as 0
+	Symbol            string `csv:"symbol" json:"-"`
+	Effect            string `csv:"effect" json:"-"`
+	Symbol2           string `csv:"symbol_2" json:"-"`
+	Effect2           string `csv:"effect_2" json:"-"`
+	Symbol3           string `csv:"symbol_3" json:"-"`
+	Effect3           string `csv:"effect_3" json:"-"`
+	Strength          Stat   `csv:"strength" json:"-"`
+	Intelligence      Stat   `csv:"intelligence" json:"-"`
+	Special           Stat   `csv:"special" json:"-"`
 	PreviewURL        string `csv:"preview_url" json:"previewUrl,omitempty"`
 	Previewer         string `csv:"previewer" json:"previewer,omitempty"`
 	PreviewActive     bool   `csv:"preview_active" json:"-"` // TODO: Should we add this to json?
@@ -23,6 +27,9 @@ type Card struct {
 	MediumImageURL    string `csv:"medium_image_url" json:"mediumImage"`
 	SmallImageURL     string `csv:"small_image_url" json:"smallImage"`
 	ThumbnailImageURL string `csv:"thumbnail_image_url" json:"thumbnailImage"`
+
+	// ImageVariants are the URLs of extra image encodings, keyed by "<size>.<format>"
+	ImageVariants map[string]string `csv:"-" json:"imageVariants,omitempty"`
 }
 
 // Filename turns the UID into a image filename
@@ -30,6 +37,44 @@ func (card *Card) Filename() string {
 	return card.UID + ".jpg"
 }
 
+// ImageURL returns the URL of an image size, e.g. "large"
+func (card *Card) ImageURL(size string) string {
+	switch size {
+	case "original":
+		return card.OriginalImageURL
+	case "large":
+		return card.LargeImageURL
+	case "medium":
+		return card.MediumImageURL
+	case "small":
+		return card.SmallImageURL
+	case "thumbnail":
+		return card.ThumbnailImageURL
+	}
+
+	return ""
+}
+
+// SetImageURL sets the URL of an image size, returning false if the csv has no column for it
+func (card *Card) SetImageURL(size string, url string) bool {
+	switch size {
+	case "original":
+		card.OriginalImageURL = url
+	case "large":
+		card.LargeImageURL = url
+	case "medium":
+		card.MediumImageURL = url
+	case "small":
+		card.SmallImageURL = url
+	case "thumbnail":
+		card.ThumbnailImageURL = url
+	default:
+		return false
+	}
+
+	return true
+}
+
 // HasPreview returns true if Preview fields exist
 func (card *Card) HasPreview() bool {
 	if card.PreviewURL == "" || card.Previewer == "" {
@@ -0,0 +1,76 @@
+package csv
+
+// MaxEffects is the number of symbol and effect column pairs in the sheet
+const MaxEffects = 3
+
+// Effect is one part of a card's rules text, with the symbol it starts with,
+// e.g. "Constant" or "Push"
+type Effect struct {
+	Symbol string
+	Text   string
+}
+
+// IsEmpty returns true if both columns of the Effect are blank
+func (effect Effect) IsEmpty() bool {
+	return effect.Symbol == "" && effect.Text == ""
+}
+
+// Effects returns the card's effects up to the last pair of columns that is
+// filled in, so each effect's index matches its columns
+func (card *Card) Effects() []Effect {
+	var effects []Effect
+	for i, columns := range card.effectColumns() {
+		effect := Effect{Symbol: *columns[0], Text: *columns[1]}
+		if effect.IsEmpty() == false {
+			for len(effects) < i {
+				effects = append(effects, Effect{})
+			}
+			effects = append(effects, effect)
+		}
+	}
+
+	return effects
+}
+
+// EffectAt returns the effect in a pair of columns, starting at 0
+func (card *Card) EffectAt(i int) Effect {
+	columns := card.effectColumns()
+	if i < 0 || i >= len(columns) {
+		return Effect{}
+	}
+
+	return Effect{Symbol: *columns[i][0], Text: *columns[i][1]}
+}
+
+// SetEffectAt sets a pair of effect columns, returning false if the csv has no columns for it
+func (card *Card) SetEffectAt(i int, effect Effect) bool {
+	columns := card.effectColumns()
+	if i < 0 || i >= len(columns) {
+		return false
+	}
+
+	*columns[i][0] = effect.Symbol
+	*columns[i][1] = effect.Text
+	return true
+}
+
+// SetEffects replaces every effect, returning false if there are more than MaxEffects
+func (card *Card) SetEffects(effects []Effect) bool {
+	for i := 0; i < MaxEffects; i++ {
+		var effect Effect
+		if i < len(effects) {
+			effect = effects[i]
+		}
+		card.SetEffectAt(i, effect)
+	}
+
+	return len(effects) <= MaxEffects
+}
+
+func (card *Card) effectColumns() [MaxEffects][2]*string {
+	return [MaxEffects][2]*string{
+		{&card.Symbol, &card.Effect},
+		{&card.Symbol2, &card.Effect2},
+		{&card.Symbol3, &card.Effect3},
+	}
+}
@@ -0,0 +1,16 @@
+package csv
+
+import (
+	"io"
+	"os"
+)
+
+// FileSource reads the csv from a local file
+type FileSource struct {
+	Path string
+}
+
+// Open opens the file
+func (src FileSource) Open() (io.ReadCloser, error) {
+	return os.Open(src.Path)
+}
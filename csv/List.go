@@ -0,0 +1,27 @@
+package csv
+
+import "strings"
+
+// ListSeparator separates the values of a List column, e.g. "Hero; Villain"
+const ListSeparator = ";"
+
+// List is a column with several values, like the traits of a card
+type List []string
+
+// UnmarshalCSV splits the column on ListSeparator, dropping blank values
+func (list *List) UnmarshalCSV(value string) error {
+	var values List
+	for _, v := range strings.Split(value, ListSeparator) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	*list = values
+	return nil
+}
+
+// MarshalCSV joins the values, or writes a blank column
+func (list List) MarshalCSV() (string, error) {
+	return strings.Join(list, ListSeparator+" "), nil
+}
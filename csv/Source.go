@@ -0,0 +1,33 @@
+package csv
+
+import (
+	"io"
+	"strings"
+)
+
+// Source is somewhere the card csv can be read from
+type Source interface {
+	Open() (io.ReadCloser, error)
+}
+
+// SheetSource is the Google Sheet the cards are maintained in
+var SheetSource = URLSource{URL: csvURL}
+
+// ParseSource turns a flag value into a Source:
+// "" or "sheet" for the Google Sheet, "-" for stdin,
+// an http(s) URL, or a path to a local file
+func ParseSource(s string) Source {
+	if s == "" || s == "sheet" {
+		return SheetSource
+	}
+
+	if s == "-" {
+		return StdinSource{}
+	}
+
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return URLSource{URL: s}
+	}
+
+	return FileSource{Path: s}
+}
@@ -0,0 +1,119 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatNames are the stat columns, in the order they appear in the sheet
+var StatNames = []string{"strength", "intelligence", "special"}
+
+// Stat is a stat rank column. Cards without the stat leave it blank, which is
+// different from a rank of 0
+type Stat struct {
+	Rank  int
+	Valid bool
+}
+
+// NewStat returns a Stat with a rank
+func NewStat(rank int) Stat {
+	return Stat{Rank: rank, Valid: true}
+}
+
+// UnmarshalCSV reads the column, which is blank or a whole number
+func (stat *Stat) UnmarshalCSV(value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		*stat = Stat{}
+		return nil
+	}
+
+	rank, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("Invalid stat rank: %q", value)
+	}
+
+	*stat = NewStat(rank)
+	return nil
+}
+
+// MarshalCSV writes the rank, or a blank column
+func (stat Stat) MarshalCSV() (string, error) {
+	if stat.Valid == false {
+		return "", nil
+	}
+
+	return strconv.Itoa(stat.Rank), nil
+}
+
+// Value returns the rank, or nil if the column is blank
+func (stat Stat) Value() interface{} {
+	if stat.Valid == false {
+		return nil
+	}
+
+	return stat.Rank
+}
+
+// Stat returns a stat column by name, e.g. "strength"
+func (card *Card) Stat(name string) Stat {
+	switch name {
+	case "strength":
+		return card.Strength
+	case "intelligence":
+		return card.Intelligence
+	case "special":
+		return card.Special
+	}
+
+	return Stat{}
+}
+
+// SetStat sets a stat column by name
+func (card *Card) SetStat(name string, stat Stat) {
+	switch name {
+	case "strength":
+		card.Strength = stat
+	case "intelligence":
+		card.Intelligence = stat
+	case "special":
+		card.Special = stat
+	}
+}
+
+// CheckStat returns an error if a stat column is blank for a type of card that
+// needs every stat, or filled in for a type that can't have them. Battle cards
+// can leave any stat blank, see CheckStats
+func (card *Card) CheckStat(name string) error {
+	stat := card.Stat(name)
+
+	switch card.Type {
+	case "Character":
+		if stat.Valid == false {
+			return fmt.Errorf("required for %s cards", card.Type)
+		}
+	case "Event":
+		if stat.Valid {
+			return errors.New("must be blank for Event cards")
+		}
+	}
+
+	return nil
+}
+
+// CheckStats returns an error if a Battle card has none of its stat columns filled in
+func (card *Card) CheckStats() error {
+	if card.Type != "Battle" {
+		return nil
+	}
+
+	for _, name := range StatNames {
+		if card.Stat(name).Valid {
+			return nil
+		}
+	}
+
+	return errors.New("at least one is required for Battle cards")
+}
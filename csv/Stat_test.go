@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatUnmarshalCSV(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Stat
+		wantErr bool
+	}{
+		{"", Stat{}, false},
+		{"  ", Stat{}, false},
+		{"0", NewStat(0), false},
+		{"3", NewStat(3), false},
+		{" 12 ", NewStat(12), false},
+		{"-1", NewStat(-1), false},
+		{"x", Stat{}, true},
+		{"1.5", Stat{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			stat := NewStat(9)
+			err := stat.UnmarshalCSV(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("UnmarshalCSV(%q) error = %v, want error %v", test.value, err, test.wantErr)
+			}
+			if err == nil && stat != test.want {
+				t.Errorf("UnmarshalCSV(%q) = %+v, want %+v", test.value, stat, test.want)
+			}
+		})
+	}
+}
+
+func TestStatMarshalCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		stat      Stat
+		want      string
+		wantValue interface{}
+	}{
+		{"blank", Stat{}, "", nil},
+		{"blank with a rank", Stat{Rank: 3}, "", nil},
+		{"zero", NewStat(0), "0", 0},
+		{"rank", NewStat(5), "5", 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.stat.MarshalCSV()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("MarshalCSV = %q, want %q", got, test.want)
+			}
+			if value := test.stat.Value(); reflect.DeepEqual(value, test.wantValue) == false {
+				t.Errorf("Value = %v, want %v", value, test.wantValue)
+			}
+		})
+	}
+}
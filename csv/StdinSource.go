@@ -0,0 +1,15 @@
+package csv
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// StdinSource reads the csv from stdin
+type StdinSource struct{}
+
+// Open returns stdin, which is left open when closed
+func (src StdinSource) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(os.Stdin), nil
+}
@@ -0,0 +1,27 @@
+package csv
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// URLSource downloads the csv from an http(s) URL
+type URLSource struct {
+	URL string
+}
+
+// Open requests the URL and returns the response body
+func (src URLSource) Open() (io.ReadCloser, error) {
+	resp, err := http.Get(src.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Unable to fetch %s: %s", src.URL, resp.Status)
+	}
+
+	return resp.Body, nil
+}
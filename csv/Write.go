@@ -0,0 +1,12 @@
+package csv
+
+import (
+	"io"
+
+	"github.com/gocarina/gocsv"
+)
+
+// Write writes Cards as csv in the spreadsheet's column order
+func Write(w io.Writer, cards []*Card) error {
+	return gocsv.Marshal(cards, w)
+}
@@ -1,8 +1,6 @@
 package csv
 
 import (
-	"net/http"
-
 	"github.com/gocarina/gocsv"
 )
 
@@ -10,15 +8,17 @@ const (
 	csvURL = "https://docs.google.com/spreadsheets/d/1w2TuX7u_wdxFXnUWb_KyRS6o_8vxAEjZV5u5BpkOuI0/export?exportFormat=csv"
 )
 
-// Fetch pulls the csv and generates Cards
-func Fetch() ([]*Card, error) {
-	resp, err := http.Get(csvURL)
+// Fetch reads the csv from a Source and generates Cards
+func Fetch(src Source) ([]*Card, error) {
+	body, err := src.Open()
 	if err != nil {
 		return nil, err
 	}
 
+	defer body.Close()
+
 	cards := []*Card{}
-	if err := gocsv.Unmarshal(resp.Body, &cards); err != nil {
+	if err := gocsv.Unmarshal(body, &cards); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"mxdb-tools/diff"
+	"mxdb-tools/gql"
+	"mxdb-tools/merge"
+	"os"
+	"time"
+)
+
+var diffFormat string
+var diffFrom string
+var diffTo string
+
+func runDiff(args []string) int {
+	ok := parseFlags("diff", args, func(flags *flag.FlagSet) {
+		planFlags(flags)
+		flags.StringVar(&diffFormat, "format", defaultDiffFormat(), "Output format: text, color, unified or json")
+		flags.StringVar(&diffFrom, "from", "", "Compare this snapshot with --to instead of comparing the csv with the API")
+		flags.StringVar(&diffTo, "to", "api", "Snapshot to compare --from with, or api for the live API")
+	})
+	if ok == false {
+		return exitUsage
+	}
+	if diff.IsFormat(diffFormat) == false {
+		log.Println("Invalid --format value:", diffFormat)
+		return exitUsage
+	}
+
+	if diffFrom != "" {
+		return diffSnapshots()
+	}
+
+	_, p, code, err := buildPlan()
+	if err != nil {
+		log.Println(err)
+		return code
+	}
+
+	if err := diff.Write(os.Stdout, p.Diffs(), diffFormat); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+	merge.PrintConflicts(os.Stderr, p.Conflicts())
+	for uid, err := range p.Refused {
+		log.Println("Not compared:", uid, err)
+	}
+
+	return exitOK
+}
+
+// diffSnapshots compares the --from snapshot with the --to snapshot or the live API
+func diffSnapshots() int {
+	from, err := readDiffSnapshot(diffFrom)
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	var to []*gql.Card
+	if diffTo == "api" {
+		client, err := newClient()
+		if err != nil {
+			log.Println(err)
+			return exitUsage
+		}
+
+		to, err = client.FetchCards()
+		if err != nil {
+			log.Println(err)
+			return exitFailure
+		}
+	} else {
+		to, err = readDiffSnapshot(diffTo)
+		if err != nil {
+			log.Println(err)
+			return exitFailure
+		}
+	}
+
+	diffs, refused := diff.Cards(from, to)
+	if err := diff.Write(os.Stdout, diffs, diffFormat); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+	for uid, err := range refused {
+		log.Println("Not compared:", uid, err)
+	}
+
+	return exitOK
+}
+
+func readDiffSnapshot(path string) ([]*gql.Card, error) {
+	snapshot, err := gql.ReadSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Read the snapshot of %s from %s", snapshot.Endpoint, snapshot.CreatedAt.Format(time.RFC1123))
+
+	return snapshot.Cards, nil
+}
+
+// defaultDiffFormat colors the diff when it is written to a terminal
+func defaultDiffFormat() string {
+	info, err := os.Stdout.Stat()
+	if err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return diff.Color
+	}
+
+	return diff.Text
+}
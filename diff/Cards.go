@@ -0,0 +1,50 @@
+package diff
+
+import (
+	"mxdb-tools/gql"
+)
+
+// Cards compares two sets of cards from the API, such as snapshots saved before
+// and after a release. Cards only in to are created and cards only in from are
+// deleted. Cards that can't be compared are returned as errors by UID
+func Cards(from []*gql.Card, to []*gql.Card) ([]*Diff, map[string]error) {
+	fromCards := make(map[string]*gql.Card)
+	for _, card := range from {
+		fromCards[card.UID] = card
+	}
+
+	toUIDs := make(map[string]bool)
+	for _, card := range to {
+		toUIDs[card.UID] = true
+	}
+
+	refused := make(map[string]error)
+	var creates, updates, deletes []*Diff
+	for _, card := range to {
+		row, err := card.CSV()
+		if err != nil {
+			refused[card.UID] = err
+			continue
+		}
+
+		current := fromCards[card.UID]
+		if current == nil {
+			creates = append(creates, Card(row, nil))
+			continue
+		}
+
+		d := Card(row, current)
+		if d.IsEmpty() == false {
+			updates = append(updates, d)
+		}
+	}
+
+	for _, card := range from {
+		if toUIDs[card.UID] == false {
+			deletes = append(deletes, Card(nil, card))
+		}
+	}
+
+	diffs := append(creates, updates...)
+	return append(diffs, deletes...), refused
+}
@@ -0,0 +1,35 @@
+package diff
+
+import (
+	"mxdb-tools/gql"
+	"reflect"
+	"testing"
+)
+
+func TestCards(t *testing.T) {
+	from := []*gql.Card{
+		{UID: "1", Title: "A", Type: "Event"},
+		{UID: "2", Title: "B", Type: "Event"},
+		{UID: "4", Title: "D", Type: "Event"},
+	}
+	to := []*gql.Card{
+		{UID: "1", Title: "A2", Type: "Event"},
+		{UID: "3", Title: "C", Type: "Event"},
+		{UID: "4", Title: "D", Type: "Event"},
+		{UID: "5", Type: "Event", Effects: gql.Effects{{Text: "1"}, {Text: "2"}, {Text: "3"}, {Text: "4"}}},
+	}
+
+	diffs, refused := Cards(from, to)
+
+	var got []string
+	for _, d := range diffs {
+		got = append(got, string(d.Op)+" "+d.UID)
+	}
+	want := []string{"create 3", "update 1", "delete 2"}
+	if reflect.DeepEqual(got, want) == false {
+		t.Errorf("Cards = %v, want %v", got, want)
+	}
+	if len(refused) != 1 || refused["5"] == nil {
+		t.Errorf("refused = %v, want card 5, which has too many effects", refused)
+	}
+}
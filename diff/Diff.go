@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"reflect"
+)
+
+// Op is what happens to a card or a field
+type Op string
+
+const (
+	// Create is a card in the csv that isn't in the API
+	Create Op = "create"
+	// Update is a card in both the csv and the API
+	Update Op = "update"
+	// Delete is a card in the API that isn't in the csv
+	Delete Op = "delete"
+
+	// Add is a field that is empty in the API
+	Add Op = "add"
+	// Remove is a field that is empty in the csv
+	Remove Op = "remove"
+	// Change is a field set to different values in the csv and the API
+	Change Op = "change"
+)
+
+// Field is a single difference, named by its path in the card such as effect.text
+type Field struct {
+	Path string      `json:"path"`
+	Op   Op          `json:"op"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// Diff is every difference between a csv.Card and the card in the API
+type Diff struct {
+	UID    string  `json:"uid"`
+	Op     Op      `json:"op"`
+	Fields []Field `json:"fields"`
+}
+
+// New returns an empty Diff for a card
+func New(uid string, op Op) *Diff {
+	return &Diff{UID: uid, Op: op}
+}
+
+// Card compares a csv.Card with the card in the API. If current is nil the card
+// is compared with an empty card and the Diff creates it, and if card is nil the
+// Diff deletes it
+func Card(card *csv.Card, current *gql.Card) *Diff {
+	switch {
+	case current == nil:
+		current = &gql.Card{}
+		d := New(card.UID, Create)
+		d.compare(card, current)
+		return d
+	case card == nil:
+		d := New(current.UID, Delete)
+		d.compare(&csv.Card{}, current)
+		return d
+	default:
+		d := New(card.UID, Update)
+		d.compare(card, current)
+		return d
+	}
+}
+
+func (d *Diff) compare(card *csv.Card, current *gql.Card) {
+	d.Add("", current.Changes(card))
+	d.Add("effects", current.Effects.Changes(card))
+	d.Add("image", current.Image.Changes(card))
+	d.Add("preview", current.Preview.Changes(card))
+}
+
+// Add appends the Changes of one part of the card, with their fields prefixed by the part's name
+func (d *Diff) Add(prefix string, changes []gql.Change) {
+	for _, change := range changes {
+		path := change.Field
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+
+		op := Change
+		switch {
+		case d.Op == Create || isEmpty(change.Old):
+			op = Add
+		case d.Op == Delete || isEmpty(change.New):
+			op = Remove
+		}
+
+		d.Fields = append(d.Fields, Field{Path: path, Op: op, Old: change.Old, New: change.New})
+	}
+}
+
+// IsEmpty returns true if there are no differences
+func (d *Diff) IsEmpty() bool {
+	return len(d.Fields) == 0
+}
+
+// isEmpty returns true for a missing value, like the nil of a blank stat. Numbers
+// and booleans are values of their own even when they're zero, so a stat rank of
+// 0 is different from a blank one
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	}
+
+	return false
+}
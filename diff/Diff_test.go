@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"bytes"
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name   string
+		op     Op
+		change gql.Change
+		want   Op
+	}{
+		{"stat set", Update, gql.Change{Field: "stats.intelligence", Old: nil, New: 2}, Add},
+		{"stat cleared", Update, gql.Change{Field: "stats.intelligence", Old: 2, New: nil}, Remove},
+		{"stat changed to 0", Update, gql.Change{Field: "stats.intelligence", Old: 1, New: 0}, Change},
+		{"stat changed from 0", Update, gql.Change{Field: "stats.intelligence", Old: 0, New: 1}, Change},
+		{"stat 0 cleared", Update, gql.Change{Field: "stats.intelligence", Old: 0, New: nil}, Remove},
+		{"mp changed to 0", Update, gql.Change{Field: "mp", Old: 2, New: 0}, Change},
+		{"subtitle set", Update, gql.Change{Field: "subtitle", Old: "", New: "Sub"}, Add},
+		{"subtitle cleared", Update, gql.Change{Field: "subtitle", Old: "Sub", New: ""}, Remove},
+		{"traits cleared", Update, gql.Change{Field: "traits", Old: []string{"Hero"}, New: []string(nil)}, Remove},
+		{"preview deactivated", Update, gql.Change{Field: "isActive", Old: true, New: false}, Change},
+		{"created with mp", Create, gql.Change{Field: "mp", Old: 0, New: 2}, Add},
+		{"deleted with mp", Delete, gql.Change{Field: "mp", Old: 2, New: 0}, Remove},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := New("1-001", test.op)
+			d.Add("", []gql.Change{test.change})
+			if got := d.Fields[0].Op; got != test.want {
+				t.Errorf("Op = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCard(t *testing.T) {
+	card := &csv.Card{UID: "1-001", Title: "Card", Type: "Character", MP: 2, Intelligence: csv.NewStat(0)}
+	current := &gql.Card{UID: "1-001", Title: "Card", Type: "Character", MP: 2, Stats: []gql.Stats{{Type: "INTELLIGENCE", Rank: 1}}}
+
+	d := Card(card, current)
+	want := []Field{{Path: "stats.intelligence", Op: Change, Old: 1, New: 0}}
+	if d.Op != Update || reflect.DeepEqual(d.Fields, want) == false {
+		t.Errorf("Card = %s %+v, want %s %+v", d.Op, d.Fields, Update, want)
+	}
+
+	created := Card(card, nil)
+	if created.Op != Create {
+		t.Errorf("Op = %s, want %s", created.Op, Create)
+	}
+	for _, field := range created.Fields {
+		if field.Op != Add {
+			t.Errorf("%s of a created card is %s, want %s", field.Path, field.Op, Add)
+		}
+	}
+
+	deleted := Card(nil, current)
+	if deleted.Op != Delete || deleted.UID != "1-001" {
+		t.Errorf("Card = %s %s, want %s 1-001", deleted.Op, deleted.UID, Delete)
+	}
+	for _, field := range deleted.Fields {
+		if field.Op != Remove {
+			t.Errorf("%s of a deleted card is %s, want %s", field.Path, field.Op, Remove)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d := New("1-001", Update)
+	d.Add("", []gql.Change{
+		{Field: "stats.intelligence", Old: 1, New: 0},
+		{Field: "subtitle", Old: "", New: "Sub"},
+		{Field: "stats.special", Old: 3, New: nil},
+	})
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{Text, "~ update 1-001\n  ~ stats.intelligence: 1 -> 0\n  + subtitle: \"Sub\"\n  - stats.special: 3\n"},
+		{Unified, "--- api/1-001\n+++ csv/1-001\n@@ stats.intelligence @@\n-1\n+0\n@@ subtitle @@\n+Sub\n@@ stats.special @@\n-3\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, []*Diff{d}, test.format); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("Write =\n%s\nwant\n%s", buf.String(), test.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, []*Diff{d}, JSON); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), `"op": "change"`) == false || strings.Contains(buf.String(), `"new": 0`) == false {
+		t.Errorf("Write JSON = %s, want the change to 0", buf.String())
+	}
+
+	if err := Write(&buf, nil, "yaml"); err == nil {
+		t.Error("Write didn't fail for an unknown format")
+	}
+}
@@ -0,0 +1,179 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Formats that a Diff can be written in
+const (
+	Text    = "text"
+	Color   = "color"
+	Unified = "unified"
+	JSON    = "json"
+)
+
+const (
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	reset  = "\x1b[0m"
+)
+
+// IsFormat returns true if the Diffs can be written in format
+func IsFormat(format string) bool {
+	switch format {
+	case Text, Color, Unified, JSON:
+		return true
+	}
+	return false
+}
+
+// Write renders the Diffs in one of the formats
+func Write(w io.Writer, diffs []*Diff, format string) error {
+	switch format {
+	case Text:
+		for _, d := range diffs {
+			d.WriteText(w, false)
+		}
+	case Color:
+		for _, d := range diffs {
+			d.WriteText(w, true)
+		}
+	case Unified:
+		for _, d := range diffs {
+			d.WriteUnified(w)
+		}
+	case JSON:
+		if diffs == nil {
+			diffs = []*Diff{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diffs)
+	default:
+		return fmt.Errorf("Invalid diff format: %s", format)
+	}
+
+	return nil
+}
+
+// WriteText writes the Diff with one line per field, optionally colored for a terminal
+func (d *Diff) WriteText(w io.Writer, color bool) {
+	paint := func(op Op, s string) string {
+		if color == false {
+			return s
+		}
+		switch op {
+		case Create, Add:
+			return green + s + reset
+		case Delete, Remove:
+			return red + s + reset
+		}
+		return yellow + s + reset
+	}
+
+	fmt.Fprintln(w, paint(d.Op, fmt.Sprintf("%s %s %s", symbol(d.Op), d.Op, d.UID)))
+	for _, field := range d.Fields {
+		var line string
+		switch field.Op {
+		case Add:
+			line = fmt.Sprintf("  %s %s: %s", symbol(field.Op), field.Path, format(field.New))
+		case Remove:
+			line = fmt.Sprintf("  %s %s: %s", symbol(field.Op), field.Path, format(field.Old))
+		default:
+			line = fmt.Sprintf("  %s %s: %s -> %s", symbol(field.Op), field.Path, format(field.Old), format(field.New))
+		}
+		fmt.Fprintln(w, paint(field.Op, line))
+	}
+}
+
+// WriteUnified writes the Diff like a unified diff of the API against the csv,
+// with a hunk for every field
+func (d *Diff) WriteUnified(w io.Writer) {
+	from := "api/" + d.UID
+	to := "csv/" + d.UID
+	switch d.Op {
+	case Create:
+		from = "/dev/null"
+	case Delete:
+		to = "/dev/null"
+	}
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", from, to)
+	for _, field := range d.Fields {
+		fmt.Fprintf(w, "@@ %s @@\n", field.Path)
+		for _, line := range lines(field.Old) {
+			fmt.Fprintf(w, "-%s\n", line)
+		}
+		for _, line := range lines(field.New) {
+			fmt.Fprintf(w, "+%s\n", line)
+		}
+	}
+}
+
+func symbol(op Op) string {
+	switch op {
+	case Create, Add:
+		return "+"
+	case Delete, Remove:
+		return "-"
+	}
+	return "~"
+}
+
+// format writes a value the way it would appear in Go, with maps in key order
+func format(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	if m, ok := v.(map[string]string); ok {
+		var pairs []string
+		for _, key := range sortedKeys(m) {
+			pairs = append(pairs, fmt.Sprintf("%q: %q", key, m[key]))
+		}
+		return "{" + strings.Join(pairs, ", ") + "}"
+	}
+	if list, ok := v.([]string); ok {
+		var values []string
+		for _, value := range list {
+			values = append(values, fmt.Sprintf("%q", value))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// lines splits a value into the lines of a unified diff. Empty values have no lines
+func lines(v interface{}) []string {
+	if isEmpty(v) {
+		return nil
+	}
+
+	switch value := v.(type) {
+	case string:
+		return strings.Split(value, "\n")
+	case []string:
+		return value
+	case map[string]string:
+		var result []string
+		for _, key := range sortedKeys(value) {
+			result = append(result, key+": "+value[key])
+		}
+		return result
+	}
+
+	return []string{fmt.Sprint(v)}
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"mxdb-tools/csv"
+	"mxdb-tools/export"
+	"os"
+	"sort"
+)
+
+var exportFrom string
+var exportOut string
+
+// runExport writes the cards from the --source or the API as a static dataset
+// of JSON, csv and SQLite. Without --out the cards are written to stdout as csv,
+// e.g. to keep a local copy of the sheet to use as a --source later
+func runExport(args []string) int {
+	ok := parseFlags("export", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&exportFrom, "from", "csv", "Export the cards from the csv --source or the api")
+		flags.StringVar(&exportOut, "out", "", "Directory to write the JSON, csv and SQLite dataset to")
+	})
+	if ok == false {
+		return exitUsage
+	}
+
+	var cards []*export.Card
+	var from string
+	switch exportFrom {
+	case "csv":
+		csvCards, err := fetchCards()
+		if err != nil {
+			log.Println(err)
+			return exitFailure
+		}
+		cards = export.FromCSV(csvCards)
+		from = "csv:" + source
+	case "api":
+		client, err := newClient()
+		if err != nil {
+			log.Println(err)
+			return exitUsage
+		}
+		gqlCards, err := client.FetchCards()
+		if err != nil {
+			log.Println(err)
+			return exitFailure
+		}
+		cards = export.FromAPI(gqlCards)
+		from = "api:" + endpoint
+	default:
+		log.Println("Invalid --from value:", exportFrom)
+		return exitUsage
+	}
+
+	dataset := export.New(from, cards)
+
+	if exportOut == "" {
+		rows, leftOut := dataset.CSV()
+		if err := csv.Write(os.Stdout, rows); err != nil {
+			log.Println(err)
+			return exitFailure
+		}
+		return reportLeftOut(leftOut)
+	}
+
+	leftOut, err := dataset.Write(exportOut)
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d cards to %s\n", len(dataset.Cards), exportOut)
+
+	return reportLeftOut(leftOut)
+}
+
+// reportLeftOut logs the cards that the csv couldn't hold. They're in the
+// JSON and SQLite files, but the export fails so they aren't missed
+func reportLeftOut(leftOut map[string]error) int {
+	if len(leftOut) == 0 {
+		return exitOK
+	}
+
+	var uids []string
+	for uid := range leftOut {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		log.Println("Left out of the csv:", leftOut[uid])
+	}
+
+	return exitFailure
+}
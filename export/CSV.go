@@ -0,0 +1,39 @@
+package export
+
+import (
+	"io"
+	"mxdb-tools/csv"
+	"mxdb-tools/fs"
+)
+
+// CSV converts the cards to rows of the spreadsheet. Cards with more effects than
+// the csv has columns for are left out, and returned by UID
+func (d *Dataset) CSV() ([]*csv.Card, map[string]error) {
+	var rows []*csv.Card
+	leftOut := make(map[string]error)
+	for _, card := range d.Cards {
+		row, err := card.CSV()
+		if err != nil {
+			leftOut[card.UID] = err
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, leftOut
+}
+
+// WriteCSV writes the cards in the spreadsheet's column order, so the file can be
+// used as a --source. The cards left out of it are returned by UID
+func (d *Dataset) WriteCSV(path string) (map[string]error, error) {
+	rows, leftOut := d.CSV()
+
+	err := fs.WriteAtomic(path, func(w io.Writer) error {
+		return csv.Write(w, rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return leftOut, nil
+}
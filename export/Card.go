@@ -0,0 +1,200 @@
+package export
+
+import (
+	"fmt"
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"strings"
+)
+
+// Card is a card in the exported dataset, the same whether it came from the csv or the API
+type Card struct {
+	UID      string    `json:"uid"`
+	Rarity   string    `json:"rarity"`
+	Number   int       `json:"number"`
+	Set      string    `json:"set"`
+	Title    string    `json:"title"`
+	Subtitle string    `json:"subtitle,omitempty"`
+	Type     string    `json:"type"`
+	Traits   []string  `json:"traits,omitempty"`
+	MP       int       `json:"mp"`
+	Effects  []*Effect `json:"effects,omitempty"`
+	Stats    []*Stat   `json:"stats,omitempty"`
+	Image    *Image    `json:"image,omitempty"`
+	Preview  *Preview  `json:"preview,omitempty"`
+}
+
+// Effect is one part of the rules text of a Card, with its symbol
+type Effect struct {
+	Symbol string `json:"symbol"`
+	Text   string `json:"text,omitempty"`
+}
+
+// Stat is one of a Card's stat ranks, e.g. Strength 3
+type Stat struct {
+	Type string `json:"type"`
+	Rank int    `json:"rank"`
+}
+
+// Image is the URL of every rendition of a Card's image
+type Image struct {
+	Original  string            `json:"original"`
+	Large     string            `json:"large"`
+	Medium    string            `json:"medium"`
+	Small     string            `json:"small"`
+	Thumbnail string            `json:"thumbnail"`
+	Variants  map[string]string `json:"variants,omitempty"`
+}
+
+// Preview is where a Card was first revealed
+type Preview struct {
+	Previewer string `json:"previewer"`
+	URL       string `json:"url"`
+	IsActive  bool   `json:"isActive"`
+}
+
+// statTypes are the names the API gives the stat columns
+var statTypes = map[string]string{
+	"strength":     "Strength",
+	"intelligence": "Intelligence",
+	"special":      "Special",
+}
+
+// FromCSV converts the csv cards
+func FromCSV(cards []*csv.Card) []*Card {
+	var result []*Card
+	for _, card := range cards {
+		c := &Card{
+			UID:      card.UID,
+			Rarity:   card.Rarity,
+			Number:   card.Number,
+			Set:      card.Set,
+			Title:    card.Title,
+			Subtitle: card.Subtitle,
+			Type:     card.Type,
+			Traits:   card.Traits,
+			MP:       card.MP,
+		}
+
+		for _, effect := range card.Effects() {
+			c.Effects = append(c.Effects, &Effect{Symbol: effect.Symbol, Text: effect.Text})
+		}
+
+		for _, name := range csv.StatNames {
+			if stat := card.Stat(name); stat.Valid {
+				c.Stats = append(c.Stats, &Stat{Type: statTypes[name], Rank: stat.Rank})
+			}
+		}
+
+		if card.OriginalImageURL != "" {
+			c.Image = &Image{
+				Original:  card.OriginalImageURL,
+				Large:     card.LargeImageURL,
+				Medium:    card.MediumImageURL,
+				Small:     card.SmallImageURL,
+				Thumbnail: card.ThumbnailImageURL,
+				Variants:  card.ImageVariants,
+			}
+		}
+
+		if card.Previewer != "" || card.PreviewURL != "" || card.PreviewActive {
+			c.Preview = &Preview{Previewer: card.Previewer, URL: card.PreviewURL, IsActive: card.PreviewActive}
+		}
+
+		result = append(result, c)
+	}
+
+	return result
+}
+
+// FromAPI converts the cards fetched from the API
+func FromAPI(cards []*gql.Card) []*Card {
+	var result []*Card
+	for _, card := range cards {
+		c := &Card{
+			UID:      card.UID,
+			Rarity:   card.Rarity,
+			Number:   card.Number,
+			Set:      card.Set,
+			Title:    card.Title,
+			Subtitle: card.Subtitle,
+			Type:     card.Type,
+			Traits:   card.TraitNames(),
+			MP:       card.MP,
+		}
+
+		for _, effect := range card.Effects {
+			c.Effects = append(c.Effects, &Effect{Symbol: effect.Symbol, Text: effect.Text})
+		}
+
+		for _, stat := range card.Stats {
+			c.Stats = append(c.Stats, &Stat{Type: stat.Type, Rank: stat.Rank})
+		}
+
+		if card.Image.IsEmpty() == false {
+			c.Image = &Image{
+				Original:  card.Image.Original,
+				Large:     card.Image.Large,
+				Medium:    card.Image.Medium,
+				Small:     card.Image.Small,
+				Thumbnail: card.Image.Thumbnail,
+				Variants:  card.Image.Variants,
+			}
+		}
+
+		if card.Preview.ID != "" {
+			c.Preview = &Preview{Previewer: card.Preview.Previewer, URL: card.Preview.PreviewURL, IsActive: card.Preview.IsActive}
+		}
+
+		result = append(result, c)
+	}
+
+	return result
+}
+
+// CSV converts the Card back to a row of the spreadsheet. It is an error for the
+// Card to have more effects than the csv has columns for
+func (c *Card) CSV() (*csv.Card, error) {
+	if len(c.Effects) > csv.MaxEffects {
+		return nil, fmt.Errorf("%s has %d effects, but the csv only has columns for %d", c.UID, len(c.Effects), csv.MaxEffects)
+	}
+
+	card := &csv.Card{
+		UID:      c.UID,
+		Rarity:   c.Rarity,
+		Number:   c.Number,
+		Set:      c.Set,
+		Title:    c.Title,
+		Subtitle: c.Subtitle,
+		Type:     c.Type,
+		Traits:   c.Traits,
+		MP:       c.MP,
+	}
+
+	var effects []csv.Effect
+	for _, effect := range c.Effects {
+		effects = append(effects, csv.Effect{Symbol: effect.Symbol, Text: effect.Text})
+	}
+	card.SetEffects(effects)
+
+	for _, stat := range c.Stats {
+		card.SetStat(strings.ToLower(stat.Type), csv.NewStat(stat.Rank))
+	}
+
+	if c.Image != nil {
+		card.OriginalImageURL = c.Image.Original
+		card.LargeImageURL = c.Image.Large
+		card.MediumImageURL = c.Image.Medium
+		card.SmallImageURL = c.Image.Small
+		card.ThumbnailImageURL = c.Image.Thumbnail
+		card.ImageVariants = c.Image.Variants
+	}
+
+	if c.Preview != nil {
+		card.Previewer = c.Preview.Previewer
+		card.PreviewURL = c.Preview.URL
+		card.PreviewActive = c.Preview.IsActive
+	}
+
+	return card, nil
+}
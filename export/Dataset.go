@@ -0,0 +1,75 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DatasetVersion is the format of the Datasets written by this version
+const DatasetVersion = 2
+
+// Dataset is every card along with where and when they were exported from
+type Dataset struct {
+	Version   int       `json:"version"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"createdAt"`
+	Cards     []*Card   `json:"cards"`
+}
+
+// New creates a Dataset with the cards ordered by set and number
+func New(source string, cards []*Card) *Dataset {
+	sorted := append([]*Card{}, cards...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Set != sorted[j].Set {
+			return sorted[i].Set < sorted[j].Set
+		}
+		if sorted[i].Number != sorted[j].Number {
+			return sorted[i].Number < sorted[j].Number
+		}
+		return sorted[i].UID < sorted[j].UID
+	})
+
+	return &Dataset{
+		Version:   DatasetVersion,
+		Source:    source,
+		CreatedAt: time.Now().UTC(),
+		Cards:     sorted,
+	}
+}
+
+// Sets splits the Dataset into one Dataset per set
+func (d *Dataset) Sets() map[string]*Dataset {
+	sets := make(map[string]*Dataset)
+	for _, card := range d.Cards {
+		set := sets[card.Set]
+		if set == nil {
+			set = &Dataset{Version: d.Version, Source: d.Source, CreatedAt: d.CreatedAt}
+			sets[card.Set] = set
+		}
+		set.Cards = append(set.Cards, card)
+	}
+
+	return sets
+}
+
+// Write exports the Dataset to dir as SQLite, csv and JSON. The database is
+// written first, so an error in it leaves none of the other files behind.
+// The cards left out of the csv are returned by UID
+func (d *Dataset) Write(dir string) (map[string]error, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := d.WriteSQLite(filepath.Join(dir, "cards.db")); err != nil {
+		return nil, err
+	}
+
+	leftOut, err := d.WriteCSV(filepath.Join(dir, "cards.csv"))
+	if err != nil {
+		return nil, err
+	}
+
+	return leftOut, d.WriteJSON(dir)
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testDataset() *Dataset {
+	return New("test", []*Card{
+		{
+			UID:     "B-002",
+			Rarity:  "C",
+			Number:  2,
+			Set:     "B",
+			Title:   "Wide",
+			Type:    "Character",
+			Effects: []*Effect{{Symbol: "a"}, {Symbol: "b"}, {Symbol: "c"}, {Symbol: "d"}},
+		},
+		{
+			UID:     "A-001",
+			Rarity:  "R",
+			Number:  1,
+			Set:     "A",
+			Title:   "Narrow",
+			Type:    "Character",
+			Traits:  []string{"Hero", "Hero", "Flying"},
+			MP:      3,
+			Effects: []*Effect{{Symbol: "a", Text: "Draw a card."}},
+			Stats:   []*Stat{{Type: "Strength", Rank: 0}},
+			Image:   &Image{Original: "https://example.com/A-001.jpg", Variants: map[string]string{"webp": "https://example.com/A-001.webp"}},
+		},
+	})
+}
+
+func TestCSVLeavesOutCards(t *testing.T) {
+	rows, leftOut := testDataset().CSV()
+
+	if len(rows) != 1 || rows[0].UID != "A-001" {
+		t.Fatalf("CSV() rows = %+v, want only A-001", rows)
+	}
+	if len(leftOut) != 1 || leftOut["B-002"] == nil {
+		t.Errorf("CSV() left out %v, want B-002", leftOut)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	leftOut, err := testDataset().Write(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leftOut) != 1 || leftOut["B-002"] == nil {
+		t.Errorf("Write() left out %v, want B-002", leftOut)
+	}
+
+	for _, name := range []string{"cards.db", "cards.csv", "cards.json", "sets/A.json", "sets/B.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("Write() didn't write %s: %s", name, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, "cards.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	counts := map[string]int{
+		"cards":       2,
+		"effects":     5,
+		"traits":      2,
+		"card_traits": 2,
+		"stats":       1,
+		"images":      1,
+	}
+	for table, want := range counts {
+		var count int
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("%s has %d rows, want %d", table, count, want)
+		}
+	}
+
+	var variants string
+	if err := db.QueryRow("SELECT variants FROM images WHERE card_uid = 'A-001'").Scan(&variants); err != nil {
+		t.Fatal(err)
+	}
+	if variants != `{"webp":"https://example.com/A-001.webp"}` {
+		t.Errorf("variants = %s", variants)
+	}
+}
+
+func TestWriteSQLiteFirst(t *testing.T) {
+	dir := t.TempDir()
+
+	// A non-empty directory in the way of the database makes it fail
+	if err := os.MkdirAll(filepath.Join(dir, "cards.db", "in-the-way"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := testDataset().Write(dir); err == nil {
+		t.Fatal("Write() succeeded with the database in the way")
+	}
+
+	for _, name := range []string{"cards.csv", "cards.json", "cards.db.tmp"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); os.IsNotExist(err) == false {
+			t.Errorf("Write() left %s behind after the database failed", name)
+		}
+	}
+}
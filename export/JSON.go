@@ -0,0 +1,44 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"mxdb-tools/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// WriteJSON writes every card to dir/cards.json and the cards of each set to dir/sets/<set>.json
+func (d *Dataset) WriteJSON(dir string) error {
+	if err := writeJSON(filepath.Join(dir, "cards.json"), d); err != nil {
+		return err
+	}
+
+	setsDir := filepath.Join(dir, "sets")
+	if err := os.MkdirAll(setsDir, 0755); err != nil {
+		return err
+	}
+
+	for name, set := range d.Sets() {
+		filename := unsafeFilename.ReplaceAllString(name, "_")
+		if filename == "" {
+			filename = "_"
+		}
+		if err := writeJSON(filepath.Join(setsDir, filename+".json"), set); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(path string, d *Dataset) error {
+	return fs.WriteAtomic(path, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(d)
+	})
+}
@@ -0,0 +1,11 @@
+package export
+
+import "mxdb-tools/fs"
+
+// WriteSQLite writes the Dataset to a new SQLite database with cards, effects,
+// traits, stats and images tables
+func (d *Dataset) WriteSQLite(path string) error {
+	return fs.ReplaceAtomic(path, func(tmpPath string) error {
+		return writeSQLite(d, tmpPath)
+	})
+}
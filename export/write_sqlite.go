@@ -0,0 +1,177 @@
+package export
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	// The pure Go driver works in every build, including cross-compiled releases
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+CREATE TABLE meta (
+  version INTEGER NOT NULL,
+  source TEXT NOT NULL,
+  created_at TEXT NOT NULL
+);
+CREATE TABLE traits (
+  id INTEGER PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE cards (
+  uid TEXT PRIMARY KEY,
+  rarity TEXT NOT NULL,
+  number INTEGER NOT NULL,
+  set_code TEXT NOT NULL,
+  title TEXT NOT NULL,
+  subtitle TEXT,
+  type TEXT NOT NULL,
+  mp INTEGER NOT NULL,
+  previewer TEXT,
+  preview_url TEXT,
+  preview_active INTEGER
+);
+CREATE INDEX cards_set ON cards(set_code, number);
+CREATE TABLE card_traits (
+  card_uid TEXT NOT NULL REFERENCES cards(uid),
+  trait_id INTEGER NOT NULL REFERENCES traits(id),
+  PRIMARY KEY (card_uid, trait_id)
+);
+CREATE TABLE effects (
+  card_uid TEXT NOT NULL REFERENCES cards(uid),
+  position INTEGER NOT NULL,
+  symbol TEXT NOT NULL,
+  text TEXT,
+  PRIMARY KEY (card_uid, position)
+);
+CREATE TABLE stats (
+  card_uid TEXT NOT NULL REFERENCES cards(uid),
+  type TEXT NOT NULL,
+  rank INTEGER NOT NULL,
+  PRIMARY KEY (card_uid, type)
+);
+CREATE TABLE images (
+  card_uid TEXT PRIMARY KEY REFERENCES cards(uid),
+  original TEXT NOT NULL,
+  large TEXT,
+  medium TEXT,
+  small TEXT,
+  thumbnail TEXT,
+  variants TEXT
+);
+`
+
+func writeSQLite(d *Dataset, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := insertDataset(tx, d); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertDataset(tx *sql.Tx, d *Dataset) error {
+	_, err := tx.Exec(`INSERT INTO meta (version, source, created_at) VALUES (?, ?, ?)`,
+		d.Version, d.Source, d.CreatedAt.Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+
+	traitIDs := make(map[string]int64)
+	for _, card := range d.Cards {
+		var previewer, previewURL, previewActive interface{}
+		if card.Preview != nil {
+			previewer = card.Preview.Previewer
+			previewURL = card.Preview.URL
+			previewActive = card.Preview.IsActive
+		}
+
+		_, err := tx.Exec(`INSERT INTO cards (uid, rarity, number, set_code, title, subtitle, type, mp, previewer, preview_url, preview_active)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			card.UID, card.Rarity, card.Number, card.Set, card.Title, nullString(card.Subtitle), card.Type,
+			card.MP, previewer, previewURL, previewActive)
+		if err != nil {
+			return err
+		}
+
+		for _, trait := range card.Traits {
+			id, ok := traitIDs[trait]
+			if ok == false {
+				result, err := tx.Exec(`INSERT INTO traits (name) VALUES (?)`, trait)
+				if err != nil {
+					return err
+				}
+				if id, err = result.LastInsertId(); err != nil {
+					return err
+				}
+				traitIDs[trait] = id
+			}
+
+			// Traits are listed once per card, even if the sheet repeats one
+			_, err := tx.Exec(`INSERT OR IGNORE INTO card_traits (card_uid, trait_id) VALUES (?, ?)`, card.UID, id)
+			if err != nil {
+				return err
+			}
+		}
+
+		for i, effect := range card.Effects {
+			_, err := tx.Exec(`INSERT INTO effects (card_uid, position, symbol, text) VALUES (?, ?, ?, ?)`,
+				card.UID, i, effect.Symbol, nullString(effect.Text))
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, stat := range card.Stats {
+			_, err := tx.Exec(`INSERT INTO stats (card_uid, type, rank) VALUES (?, ?, ?)`,
+				card.UID, stat.Type, stat.Rank)
+			if err != nil {
+				return err
+			}
+		}
+
+		if card.Image != nil {
+			var variants interface{}
+			if len(card.Image.Variants) != 0 {
+				body, err := json.Marshal(card.Image.Variants)
+				if err != nil {
+					return err
+				}
+				variants = string(body)
+			}
+
+			_, err := tx.Exec(`INSERT INTO images (card_uid, original, large, medium, small, thumbnail, variants) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				card.UID, card.Image.Original, nullString(card.Image.Large), nullString(card.Image.Medium),
+				nullString(card.Image.Small), nullString(card.Image.Thumbnail), variants)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// nullString stores empty strings as NULL
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
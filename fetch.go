@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+)
+
+func runFetch(args []string) int {
+	if parseFlags("fetch", args, nil) == false {
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		log.Println(err)
+		return exitUsage
+	}
+
+	gqlCards, err := client.FetchCards()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	return printJSON(gqlCards)
+}
+
+// printJSON writes indented JSON to stdout
+func printJSON(v interface{}) int {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	return exitOK
+}
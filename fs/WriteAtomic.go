@@ -0,0 +1,42 @@
+package fs
+
+import (
+	"io"
+	"os"
+)
+
+// WriteAtomic writes to a temporary file that replaces path once write and
+// closing it succeed, so an interrupted write never leaves a truncated file
+func WriteAtomic(path string, write func(w io.Writer) error) error {
+	return ReplaceAtomic(path, func(tmpPath string) error {
+		f, err := os.Create(tmpPath)
+		if err != nil {
+			return err
+		}
+
+		if err := write(f); err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+	})
+}
+
+// ReplaceAtomic is WriteAtomic for writers that need a path, like a database
+// driver. create must make a new file at tmpPath
+func ReplaceAtomic(path string, create func(tmpPath string) error) error {
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if err := create(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return nil
+}
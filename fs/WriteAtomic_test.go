@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cards.json")
+	if err := ioutil.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := WriteAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "new" {
+		t.Errorf("WriteAtomic() wrote %q, want %q", body, "new")
+	}
+	if _, err := os.Stat(path + ".tmp"); os.IsNotExist(err) == false {
+		t.Error("WriteAtomic() left the temporary file behind")
+	}
+}
+
+func TestWriteAtomicError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cards.json")
+	if err := ioutil.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := errors.New("Failed")
+	err := WriteAtomic(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return failed
+	})
+	if err != failed {
+		t.Fatalf("WriteAtomic() error = %v, want %v", err, failed)
+	}
+
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "old" {
+		t.Errorf("WriteAtomic() replaced the file with %q after failing", body)
+	}
+	if _, err := os.Stat(path + ".tmp"); os.IsNotExist(err) == false {
+		t.Error("WriteAtomic() left the temporary file behind")
+	}
+}
+
+func TestReplaceAtomicRenameError(t *testing.T) {
+	// A non-empty directory can't be replaced by a file
+	path := filepath.Join(t.TempDir(), "cards.db")
+	if err := os.MkdirAll(filepath.Join(path, "in-the-way"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ReplaceAtomic(path, func(tmpPath string) error {
+		return ioutil.WriteFile(tmpPath, []byte("new"), 0644)
+	})
+	if err == nil {
+		t.Fatal("ReplaceAtomic() replaced a directory")
+	}
+	if _, err := os.Stat(path + ".tmp"); os.IsNotExist(err) == false {
+		t.Error("ReplaceAtomic() left the temporary file behind")
+	}
+}
@@ -0,0 +1,247 @@
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+var variablePattern = regexp.MustCompile(`\$(\w+)`)
+var definitionPattern = regexp.MustCompile(`\$(\w+)\s*:`)
+
+// Batch sends many Mutations as aliased fields of a single graphql document.
+// The server runs the fields of a mutation in order, so Mutations that depend
+// on each other can share a Batch
+type Batch struct {
+	client    *Client
+	size      int
+	keys      []string
+	mutations []*Mutation
+}
+
+// Result is the outcome of one Mutation in a Batch. Key is the value the
+// Mutation was added with, such as a card UID
+type Result struct {
+	Key      string
+	Mutation *Mutation
+	Data     json.RawMessage
+	Err      error
+}
+
+// NewBatch creates a Batch that sends at most size Mutations per request
+func (client *Client) NewBatch(size int) *Batch {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Batch{client: client, size: size}
+}
+
+// Add queues a Mutation to be sent
+func (batch *Batch) Add(key string, m *Mutation) {
+	batch.keys = append(batch.keys, key)
+	batch.mutations = append(batch.mutations, m)
+}
+
+// Len returns the number of queued Mutations
+func (batch *Batch) Len() int {
+	return len(batch.mutations)
+}
+
+// Send makes a request for every size Mutations and returns a Result for each, in
+// the order they were added. Mutations added one after another with the same key
+// are always sent in the same request, even if there are more than size of them.
+// The Batch is empty afterwards
+func (batch *Batch) Send() []*Result {
+	var results []*Result
+	groups := keyGroups(batch.keys)
+	for len(groups) != 0 {
+		n := 1
+		for n < len(groups) && groups[n][1]-groups[0][0] <= batch.size {
+			n++
+		}
+
+		start, end := groups[0][0], groups[n-1][1]
+		results = append(results, batch.send(batch.keys[start:end], batch.mutations[start:end])...)
+		groups = groups[n:]
+	}
+
+	batch.keys = nil
+	batch.mutations = nil
+
+	return results
+}
+
+// keyGroups splits keys into runs of the same key, as pairs of their start and end
+func keyGroups(keys []string) [][2]int {
+	var groups [][2]int
+	for i := range keys {
+		if len(groups) != 0 && keys[i] == keys[i-1] {
+			groups[len(groups)-1][1] = i + 1
+		} else {
+			groups = append(groups, [2]int{i, i + 1})
+		}
+	}
+
+	return groups
+}
+
+func (batch *Batch) send(keys []string, mutations []*Mutation) []*Result {
+	results := make([]*Result, len(mutations))
+	aliases := make(map[string]*Result)
+	for i, m := range mutations {
+		results[i] = &Result{Key: keys[i], Mutation: m}
+		aliases[alias(i)] = results[i]
+	}
+
+	failAll := func(err error) []*Result {
+		for _, result := range results {
+			result.Err = err
+		}
+		return results
+	}
+
+	var definitions []string
+	var selections []string
+	variables := make(map[string]json.RawMessage)
+	for i, m := range mutations {
+		definition, selection, err := m.aliased(alias(i), variables)
+		if err != nil {
+			return failAll(err)
+		}
+		if definition != "" {
+			definitions = append(definitions, definition)
+		}
+		selections = append(selections, selection)
+	}
+
+	document := "mutation Batch"
+	if len(definitions) != 0 {
+		document += "(" + strings.Join(definitions, " ") + ")"
+	}
+	document += " { " + strings.Join(selections, " ") + " }"
+
+	reqBody, err := queryToRequest([]byte(document), variables)
+	if err != nil {
+		return failAll(err)
+	}
+
+	status, respBody, err := batch.client.makeRequest(reqBody, false)
+	if err != nil {
+		return failAll(err)
+	}
+
+	resp, err := parseResponse(status, respBody)
+	if err != nil {
+		return failAll(err)
+	}
+
+	data := make(map[string]json.RawMessage)
+	if isNull(resp.Data) == false {
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			return failAll(err)
+		}
+	}
+
+	// Errors without a path, such as a syntax error, belong to the whole document
+	aliasErrors := make(map[string]*GraphQLError)
+	documentErr := &GraphQLError{StatusCode: status}
+	for _, detail := range resp.Errors {
+		name := ""
+		if len(detail.Path) != 0 {
+			name, _ = detail.Path[0].(string)
+		}
+
+		if aliases[name] == nil {
+			documentErr.Errors = append(documentErr.Errors, detail)
+			continue
+		}
+		if aliasErrors[name] == nil {
+			aliasErrors[name] = &GraphQLError{StatusCode: status}
+		}
+		aliasErrors[name].Errors = append(aliasErrors[name].Errors, detail)
+	}
+
+	// A document rejected as a whole, e.g. for one invalid variable, ran none of
+	// its Mutations. Sending each key's on their own finds the ones at fault
+	if groups := keyGroups(keys); len(groups) > 1 && len(documentErr.Errors) != 0 && len(aliasErrors) == 0 && isNull(resp.Data) {
+		log.Printf("Batch rejected, sending its %d keys separately: %s", len(groups), documentErr)
+
+		results = nil
+		for _, group := range groups {
+			results = append(results, batch.send(keys[group[0]:group[1]], mutations[group[0]:group[1]])...)
+		}
+		return results
+	}
+
+	for name, result := range aliases {
+		switch {
+		case aliasErrors[name] != nil:
+			result.Err = aliasErrors[name]
+		case isNull(data[name]) == false:
+			result.Data = data[name]
+		case len(documentErr.Errors) != 0:
+			result.Err = documentErr
+		default:
+			result.Err = fmt.Errorf("No result for %s", result.Mutation.Name)
+		}
+	}
+
+	return results
+}
+
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+func alias(i int) string {
+	return fmt.Sprintf("m%d", i)
+}
+
+// aliased rewrites the Mutation so it can be combined with others. Its variables
+// are prefixed with the alias and added to variables, keeping only the ones the
+// query declares
+func (m *Mutation) aliased(alias string, variables map[string]json.RawMessage) (string, string, error) {
+	query := string(m.Query)
+
+	open := strings.Index(query, "{")
+	close := strings.LastIndex(query, "}")
+	if open == -1 || close < open {
+		return "", "", fmt.Errorf("Invalid mutation: %s", m.Name)
+	}
+
+	var definition string
+	header := query[:open]
+	if start := strings.Index(header, "("); start != -1 {
+		end := strings.LastIndex(header, ")")
+		if end < start {
+			return "", "", fmt.Errorf("Invalid mutation: %s", m.Name)
+		}
+		definition = header[start+1 : end]
+	}
+
+	values := make(map[string]json.RawMessage)
+	if m.Variables != nil {
+		body, err := json.Marshal(m.Variables)
+		if err != nil {
+			return "", "", err
+		}
+		if err := json.Unmarshal(body, &values); err != nil {
+			return "", "", err
+		}
+	}
+
+	for _, match := range definitionPattern.FindAllStringSubmatch(definition, -1) {
+		if value, ok := values[match[1]]; ok {
+			variables[alias+"_"+match[1]] = value
+		}
+	}
+
+	rename := "$$" + alias + "_${1}"
+	definition = variablePattern.ReplaceAllString(definition, rename)
+	selection := alias + ": " + strings.TrimSpace(variablePattern.ReplaceAllString(query[open+1:close], rename))
+
+	return definition, selection, nil
+}
@@ -0,0 +1,269 @@
+package gql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestMutationAliased(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		variables      interface{}
+		wantDefinition string
+		wantSelection  string
+		wantVariables  map[string]string
+	}{
+		{
+			name:          "no variables",
+			query:         "mutation { deleteAll { count } }",
+			wantSelection: "m0: deleteAll { count }",
+			wantVariables: map[string]string{},
+		},
+		{
+			name:           "variables",
+			query:          "mutation DeleteCard($id: ID!) {\n  deleteCard(id: $id) { id }\n}",
+			variables:      map[string]string{"id": "abc"},
+			wantDefinition: "$m0_id: ID!",
+			wantSelection:  "m0: deleteCard(id: $m0_id) { id }",
+			wantVariables:  map[string]string{"m0_id": `"abc"`},
+		},
+		{
+			name:           "variable names that prefix each other",
+			query:          "mutation UpdateCard($id: ID!, $idx: Int) { updateCard(id: $id, idx: $idx) { id } }",
+			variables:      map[string]interface{}{"id": "abc", "idx": 2},
+			wantDefinition: "$m0_id: ID!, $m0_idx: Int",
+			wantSelection:  "m0: updateCard(id: $m0_id, idx: $m0_idx) { id }",
+			wantVariables:  map[string]string{"m0_id": `"abc"`, "m0_idx": "2"},
+		},
+		{
+			name:           "variables missing from the values",
+			query:          "mutation UpdateCard($id: ID!, $title: String) { updateCard(id: $id, title: $title) { id } }",
+			variables:      map[string]string{"id": "abc"},
+			wantDefinition: "$m0_id: ID!, $m0_title: String",
+			wantSelection:  "m0: updateCard(id: $m0_id, title: $m0_title) { id }",
+			wantVariables:  map[string]string{"m0_id": `"abc"`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := &Mutation{Name: test.name, Query: []byte(test.query), Variables: test.variables}
+			variables := make(map[string]json.RawMessage)
+
+			definition, selection, err := m.aliased("m0", variables)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if definition != test.wantDefinition {
+				t.Errorf("definition = %q, want %q", definition, test.wantDefinition)
+			}
+			if selection != test.wantSelection {
+				t.Errorf("selection = %q, want %q", selection, test.wantSelection)
+			}
+
+			got := make(map[string]string)
+			for name, value := range variables {
+				got[name] = string(value)
+			}
+			if reflect.DeepEqual(got, test.wantVariables) == false {
+				t.Errorf("variables = %v, want %v", got, test.wantVariables)
+			}
+		})
+	}
+}
+
+func TestMutationAliasedInvalid(t *testing.T) {
+	for _, query := range []string{"mutation", "mutation } {", "mutation Bad($id: ID! { a }"} {
+		m := &Mutation{Name: "Bad", Query: []byte(query)}
+		if _, _, err := m.aliased("m0", make(map[string]json.RawMessage)); err == nil {
+			t.Errorf("aliased(%q) didn't fail", query)
+		}
+	}
+}
+
+var aliasPattern = regexp.MustCompile(`(m\d+): touch`)
+
+// touchHandler answers each aliased touch mutation with its id. An id of "bad"
+// fails just that alias, and "reject" fails the whole document
+func touchHandler(documents *[]string) func(w http.ResponseWriter, req *testRequest) {
+	return func(w http.ResponseWriter, req *testRequest) {
+		*documents = append(*documents, req.Query)
+
+		for _, value := range req.Variables {
+			if string(value) == `"reject"` {
+				io.WriteString(w, `{"data": null, "errors": [{"message": "Invalid variable"}]}`)
+				return
+			}
+		}
+
+		var data, errs []string
+		for _, match := range aliasPattern.FindAllStringSubmatch(req.Query, -1) {
+			alias := match[1]
+			id := string(req.Variables[alias+"_id"])
+			if id == `"bad"` {
+				data = append(data, fmt.Sprintf(`"%s": null`, alias))
+				errs = append(errs, fmt.Sprintf(`{"message": "Bad id", "path": ["%s", "touch"]}`, alias))
+				continue
+			}
+			data = append(data, fmt.Sprintf(`"%s": {"id": %s}`, alias, id))
+		}
+
+		fmt.Fprintf(w, `{"data": {%s}, "errors": [%s]}`, strings.Join(data, ", "), strings.Join(errs, ", "))
+	}
+}
+
+func touch(id string) *Mutation {
+	return &Mutation{
+		Name:      "Touch",
+		Query:     []byte("mutation Touch($id: ID!) {\n  touch(id: $id) { id }\n}"),
+		Variables: map[string]string{"id": id},
+	}
+}
+
+func TestBatchSend(t *testing.T) {
+	var documents []string
+	client := testClient(t, touchHandler(&documents))
+
+	batch := client.NewBatch(10)
+	batch.Add("A-001", touch("a"))
+	batch.Add("A-001", touch("bad"))
+	batch.Add("A-002", touch("b"))
+
+	results := batch.Send()
+	if len(documents) != 1 {
+		t.Fatalf("Send() made %d requests, want 1", len(documents))
+	}
+	if want := "mutation Batch($m0_id: ID! $m1_id: ID! $m2_id: ID!) { m0: touch(id: $m0_id) { id } m1: touch(id: $m1_id) { id } m2: touch(id: $m2_id) { id } }"; documents[0] != want {
+		t.Errorf("Send() sent %q, want %q", documents[0], want)
+	}
+	if batch.Len() != 0 {
+		t.Errorf("Send() left %d mutations in the Batch", batch.Len())
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Send() returned %d results, want 3", len(results))
+	}
+	for i, key := range []string{"A-001", "A-001", "A-002"} {
+		if results[i].Key != key {
+			t.Errorf("results[%d].Key = %s, want %s", i, results[i].Key, key)
+		}
+	}
+
+	if results[0].Err != nil || string(results[0].Data) != `{"id": "a"}` {
+		t.Errorf("results[0] = %s, %v", results[0].Data, results[0].Err)
+	}
+	if results[2].Err != nil || string(results[2].Data) != `{"id": "b"}` {
+		t.Errorf("results[2] = %s, %v", results[2].Data, results[2].Err)
+	}
+
+	var gqlErr *GraphQLError
+	if errors.As(results[1].Err, &gqlErr) == false || len(gqlErr.Errors) != 1 || gqlErr.Errors[0].Message != "Bad id" {
+		t.Errorf("results[1].Err = %#v, want only the error at m1", results[1].Err)
+	}
+}
+
+func TestBatchSendSize(t *testing.T) {
+	var documents []string
+	client := testClient(t, touchHandler(&documents))
+
+	batch := client.NewBatch(2)
+	for _, key := range []string{"A-001", "A-001", "A-001", "A-002", "A-003"} {
+		batch.Add(key, touch(key))
+	}
+
+	results := batch.Send()
+
+	// A key's mutations stay together even when there are more than size of them
+	var sizes []int
+	for _, document := range documents {
+		sizes = append(sizes, len(aliasPattern.FindAllString(document, -1)))
+	}
+	if reflect.DeepEqual(sizes, []int{3, 2}) == false {
+		t.Errorf("Send() sent documents of %v mutations, want [3 2]", sizes)
+	}
+
+	for i, result := range results {
+		if result.Err != nil || string(result.Data) != fmt.Sprintf(`{"id": "%s"}`, result.Key) {
+			t.Errorf("results[%d] = %s, %v", i, result.Data, result.Err)
+		}
+	}
+}
+
+func TestBatchSendRejected(t *testing.T) {
+	var documents []string
+	client := testClient(t, touchHandler(&documents))
+
+	batch := client.NewBatch(10)
+	batch.Add("A-001", touch("a"))
+	batch.Add("A-002", touch("reject"))
+	batch.Add("A-002", touch("b"))
+	batch.Add("A-003", touch("c"))
+
+	results := batch.Send()
+
+	// The rejected document is sent again once per key
+	if len(documents) != 4 {
+		t.Errorf("Send() made %d requests, want 4", len(documents))
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("Send() returned %d results, want 4", len(results))
+	}
+	for _, i := range []int{0, 3} {
+		if results[i].Err != nil {
+			t.Errorf("results[%d] (%s) failed with the rejected key: %s", i, results[i].Key, results[i].Err)
+		}
+	}
+	for _, i := range []int{1, 2} {
+		if results[i].Key != "A-002" || results[i].Err == nil || strings.Contains(results[i].Err.Error(), "Invalid variable") == false {
+			t.Errorf("results[%d] = %s, %v, want the rejection of A-002", i, results[i].Key, results[i].Err)
+		}
+	}
+}
+
+func TestBatchSendFailed(t *testing.T) {
+	requests := 0
+	client := testClient(t, func(w http.ResponseWriter, req *testRequest) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	batch := client.NewBatch(10)
+	batch.Add("A-001", touch("a"))
+	batch.Add("A-002", touch("b"))
+
+	for i, result := range batch.Send() {
+		if result.Err == nil {
+			t.Errorf("results[%d] succeeded with a 401", i)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Send() made %d requests, want 1", requests)
+	}
+}
+
+func TestBatchSendNoResult(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, req *testRequest) {
+		io.WriteString(w, `{"data": {"m0": {"id": "a"}}}`)
+	})
+
+	batch := client.NewBatch(10)
+	batch.Add("A-001", touch("a"))
+	batch.Add("A-002", touch("b"))
+
+	results := batch.Send()
+	if results[0].Err != nil {
+		t.Errorf("results[0].Err = %s", results[0].Err)
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "No result for Touch" {
+		t.Errorf("results[1].Err = %v, want no result", results[1].Err)
+	}
+}
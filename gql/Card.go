@@ -1,7 +1,10 @@
 package gql
 
 import (
+	"fmt"
 	"mxdb-tools/csv"
+	"sort"
+	"strings"
 )
 
 type Card struct {
@@ -13,9 +16,9 @@ type Card struct {
 	Title     string  `json:"title"`
 	Subtitle  string  `json:"subtitle"`
 	Type      string  `json:"type"`
-	Trait     Trait   `json:"trait"`
+	Traits    []Trait `json:"traits"`
 	MP        int     `json:"mp"`
-	Effect    Effect  `json:"effect"`
+	Effects   Effects `json:"effects"`
 	Stats     []Stats `json:"stats"`
 	ImageURL  string  `json:"imageUrl"`
 	Image     Image   `json:"image"`
@@ -24,7 +27,13 @@ type Card struct {
 	UpdatedAt string  `json:"updatedAt"`
 }
 
-func (c *Card) CreateImage(card *csv.Card) ([]byte, error) {
+// CreateImage creates an Image for a Card that doesn't have one
+func (client *Client) CreateImage(c *Card, card *csv.Card) ([]byte, error) {
+	return client.send(client.CreateImageMutation(c, card))
+}
+
+// CreateImageMutation is the Mutation sent by CreateImage
+func (client *Client) CreateImageMutation(c *Card, card *csv.Card) (*Mutation, error) {
 	create := Image{
 		CardID:    c.ID,
 		Original:  card.OriginalImageURL,
@@ -32,46 +41,219 @@ func (c *Card) CreateImage(card *csv.Card) ([]byte, error) {
 		Medium:    card.MediumImageURL,
 		Small:     card.SmallImageURL,
 		Thumbnail: card.ThumbnailImageURL,
+		Variants:  card.ImageVariants,
 	}
 
-	query, err := queries.MustBytes("CreateImage.graphql")
+	return newMutation("CreateImage.graphql", create)
+}
+
+// UpdateCard updates the top-level properties of a Card along with its traits and stats
+func (client *Client) UpdateCard(c *Card, card *csv.Card) ([]byte, error) {
+	return client.send(client.UpdateCardMutation(c, card))
+}
+
+// UpdateCardMutation is the Mutation sent by UpdateCard
+func (client *Client) UpdateCardMutation(c *Card, card *csv.Card) (*Mutation, error) {
+	lookups, err := client.Lookups()
 	if err != nil {
 		return nil, err
 	}
 
-	return Request(query, create)
+	type updatedCard struct {
+		Card
+		TraitIDs []string `json:"traitsIds"`
+		StatIDs  []string `json:"statsIds"`
+	}
+
+	updated := updatedCard{
+		Card: Card{
+			ID:       c.ID,
+			UID:      card.UID,
+			Rarity:   card.Rarity,
+			Number:   card.Number,
+			Set:      card.Set,
+			Title:    card.Title,
+			Subtitle: card.Subtitle,
+			Type:     card.Type,
+			MP:       card.MP,
+		},
+	}
+	if updated.StatIDs, err = lookups.StatIDs(card); err != nil {
+		return nil, err
+	}
+	if updated.StatIDs == nil {
+		updated.StatIDs = []string{}
+	}
+	if updated.TraitIDs, err = lookups.TraitIDs(card); err != nil {
+		return nil, err
+	}
+	if updated.TraitIDs == nil {
+		updated.TraitIDs = []string{}
+	}
+
+	return newMutation("UpdateCard.graphql", updated)
 }
 
-// Update updates the top-level properties of a Card, no children
-func (c *Card) Update(card *csv.Card) ([]byte, error) {
-	updated := Card{
-		ID:       c.ID,
-		UID:      card.UID,
-		Rarity:   card.Rarity,
-		Number:   card.Number,
-		Set:      card.Set,
-		Title:    card.Title,
-		Subtitle: card.Subtitle,
-		Type:     card.Type,
-		MP:       card.MP,
-	}
-
-	query, err := queries.MustBytes("UpdateCard.graphql")
+// SetCardEffects brings the Effects of a Card in line with a csv.Card
+func (client *Client) SetCardEffects(c *Card, card *csv.Card) ([]byte, error) {
+	mutations, err := client.SetCardEffectsMutations(c, card)
 	if err != nil {
 		return nil, err
 	}
 
-	return Request(query, updated)
+	var body []byte
+	for _, m := range mutations {
+		if body, err = client.send(m, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// SetCardEffectsMutations are the Mutations sent by SetCardEffects. Effects that
+// changed are updated in place, new ones are created and extra ones deleted, so
+// a failure part way through never leaves the Card without its other Effects
+func (client *Client) SetCardEffectsMutations(c *Card, card *csv.Card) ([]*Mutation, error) {
+	type setEffect struct {
+		ID     string `json:"id,omitempty"`
+		CardID string `json:"cardId,omitempty"`
+		Symbol string `json:"symbol"`
+		Text   string `json:"text"`
+	}
+
+	var mutations []*Mutation
+	effects := card.Effects()
+	for i, effect := range effects {
+		var m *Mutation
+		var err error
+		switch {
+		case i >= len(c.Effects):
+			m, err = newMutation("CreateEffect.graphql", setEffect{CardID: c.ID, Symbol: effect.Symbol, Text: effect.Text})
+		case c.Effects[i].Symbol != effect.Symbol || c.Effects[i].Text != effect.Text:
+			m, err = newMutation("UpdateEffect.graphql", setEffect{ID: c.Effects[i].ID, Symbol: effect.Symbol, Text: effect.Text})
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		mutations = append(mutations, m)
+	}
+
+	for i := len(effects); i < len(c.Effects); i++ {
+		m, err := deleteMutation("DeleteEffect.graphql", c.Effects[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		mutations = append(mutations, m)
+	}
+
+	return mutations, nil
 }
 
-// IsEqual checks if there are differences between the top-level Card properties and a csv.Card
+// IsEqual checks if there are differences between the Card properties and a csv.Card
 func (c *Card) IsEqual(card *csv.Card) bool {
-	return (c.UID == card.UID &&
-		c.Rarity == card.Rarity &&
-		c.Number == card.Number &&
-		c.Set == card.Set &&
-		c.Title == card.Title &&
-		c.Subtitle == card.Subtitle &&
-		c.Type == card.Type &&
-		c.MP == card.MP)
+	return len(c.Changes(card)) == 0
+}
+
+// Changes lists the Card properties, including traits and stats, that differ from a csv.Card
+func (c *Card) Changes(card *csv.Card) []Change {
+	var changes []Change
+	changes = appendChange(changes, "uid", c.UID, card.UID)
+	changes = appendChange(changes, "rarity", c.Rarity, card.Rarity)
+	changes = appendChange(changes, "number", c.Number, card.Number)
+	changes = appendChange(changes, "set", c.Set, card.Set)
+	changes = appendChange(changes, "title", c.Title, card.Title)
+	changes = appendChange(changes, "subtitle", c.Subtitle, card.Subtitle)
+	changes = appendChange(changes, "type", c.Type, card.Type)
+	changes = appendChange(changes, "mp", c.MP, card.MP)
+	if sameNames(c.TraitNames(), card.Traits) == false {
+		changes = appendChange(changes, "traits", c.TraitNames(), []string(card.Traits))
+	}
+
+	current := make(map[string]interface{})
+	for _, stat := range c.Stats {
+		current[strings.ToLower(stat.Type)] = stat.Rank
+	}
+	for _, name := range csv.StatNames {
+		changes = appendChange(changes, "stats."+name, current[name], card.Stat(name).Value())
+	}
+
+	return changes
+}
+
+// CheckCSV returns an error if the Card has more than the csv can hold, so
+// converting it to a row would lose some of it
+func (c *Card) CheckCSV() error {
+	if len(c.Effects) > csv.MaxEffects {
+		return fmt.Errorf("%s has %d effects, but the csv only has columns for %d", c.UID, len(c.Effects), csv.MaxEffects)
+	}
+
+	return nil
+}
+
+// CSV converts the Card back to a row of the spreadsheet, e.g. to pick up fixes
+// made in the API. It is an error for the Card not to fit in a row
+func (c *Card) CSV() (*csv.Card, error) {
+	if err := c.CheckCSV(); err != nil {
+		return nil, err
+	}
+
+	card := &csv.Card{
+		UID:               c.UID,
+		Rarity:            c.Rarity,
+		Number:            c.Number,
+		Set:               c.Set,
+		Title:             c.Title,
+		Subtitle:          c.Subtitle,
+		Type:              c.Type,
+		Traits:            c.TraitNames(),
+		MP:                c.MP,
+		PreviewURL:        c.Preview.PreviewURL,
+		Previewer:         c.Preview.Previewer,
+		PreviewActive:     c.Preview.IsActive,
+		OriginalImageURL:  c.Image.Original,
+		LargeImageURL:     c.Image.Large,
+		MediumImageURL:    c.Image.Medium,
+		SmallImageURL:     c.Image.Small,
+		ThumbnailImageURL: c.Image.Thumbnail,
+		ImageVariants:     c.Image.Variants,
+	}
+
+	for _, stat := range c.Stats {
+		card.SetStat(strings.ToLower(stat.Type), csv.NewStat(stat.Rank))
+	}
+	card.SetEffects(c.Effects.CSV())
+
+	return card, nil
+}
+
+// TraitNames returns the names of the Card's traits, or nil if it has none
+func (c *Card) TraitNames() []string {
+	var names []string
+	for _, trait := range c.Traits {
+		names = append(names, trait.Name)
+	}
+
+	return names
+}
+
+// sameNames checks if two lists have the same names, in any order
+func sameNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
 }
@@ -0,0 +1,19 @@
+package gql
+
+import "reflect"
+
+// Change describes a single field that differs between the API and a csv.Card
+type Change struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// appendChange adds a Change to the list when the old and new values differ
+func appendChange(changes []Change, field string, old, new interface{}) []Change {
+	if reflect.DeepEqual(old, new) {
+		return changes
+	}
+
+	return append(changes, Change{Field: field, Old: old, New: new})
+}
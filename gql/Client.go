@@ -0,0 +1,96 @@
+package gql
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultEndpoint is the production graphql API
+	DefaultEndpoint = "https://api.graph.cool/simple/v1/metaxdb"
+	// DefaultTimeout limits how long a single request can take
+	DefaultTimeout = 30 * time.Second
+	// DefaultRetries is how many times a query is retried after a network error, 429 or 5xx.
+	// Mutations are only retried when they never reached the server
+	DefaultRetries = 3
+	// DefaultBackoff is the wait before the first retry, which doubles for each retry after it
+	DefaultBackoff = time.Second
+)
+
+// Client makes requests against a graphql API
+type Client struct {
+	Endpoint   string
+	Token      string
+	HTTPClient *http.Client
+	Timeout    time.Duration
+	Retries    int
+	Backoff    time.Duration
+
+	limiter *limiter
+	lookups *Lookups
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithEndpoint points the Client at a different graphql API
+func WithEndpoint(endpoint string) Option {
+	return func(client *Client) {
+		client.Endpoint = endpoint
+	}
+}
+
+// WithHTTPClient sets the http.Client used to make requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.HTTPClient = httpClient
+	}
+}
+
+// WithTimeout limits how long each request, including reading its response, can take
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *Client) {
+		client.Timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a request is retried, waiting backoff before
+// the first retry and twice as long before each one after it
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(client *Client) {
+		client.Retries = retries
+		client.Backoff = backoff
+	}
+}
+
+// WithRateLimit limits the Client to a number of requests per second. Zero removes the limit
+func WithRateLimit(perSecond float64) Option {
+	return func(client *Client) {
+		client.limiter = newLimiter(perSecond)
+	}
+}
+
+// WithLookups seeds the Client's Lookups instead of fetching them from the API
+func WithLookups(lookups *Lookups) Option {
+	return func(client *Client) {
+		client.lookups = lookups
+	}
+}
+
+// NewClient creates a Client that authenticates with the token
+func NewClient(token string, options ...Option) *Client {
+	client := &Client{
+		Endpoint:   DefaultEndpoint,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+		Timeout:    DefaultTimeout,
+		Retries:    DefaultRetries,
+		Backoff:    DefaultBackoff,
+	}
+
+	for _, option := range options {
+		option(client)
+	}
+
+	return client
+}
@@ -0,0 +1,193 @@
+package gql
+
+import (
+	"encoding/json"
+	"io"
+	"mxdb-tools/csv"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRequest is the body the Client sends to the API
+type testRequest struct {
+	Query     string                     `json:"query"`
+	Variables map[string]json.RawMessage `json:"variables"`
+}
+
+// testClient points a Client at a test server. Retries back off for a
+// millisecond, so tests don't wait for them
+func testClient(t *testing.T, handler func(w http.ResponseWriter, req *testRequest), options ...Option) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected %s request with Authorization %q", r.Method, r.Header.Get("Authorization"))
+		}
+
+		req := &testRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("Invalid request body: %s", err)
+		}
+		handler(w, req)
+	}))
+	t.Cleanup(server.Close)
+
+	options = append([]Option{WithEndpoint(server.URL), WithRetries(DefaultRetries, time.Millisecond)}, options...)
+	return NewClient("token", options...)
+}
+
+func lookupsHandler(requests *int32) func(w http.ResponseWriter, req *testRequest) {
+	return func(w http.ResponseWriter, req *testRequest) {
+		atomic.AddInt32(requests, 1)
+
+		switch {
+		case strings.Contains(req.Query, "query StatRanks"):
+			io.WriteString(w, `{"data": {
+				"strength": [{"id": "s1", "rank": 1}, {"id": "s0", "rank": 0}],
+				"intelligence": [{"id": "i2", "rank": 2}],
+				"special": []
+			}}`)
+		case strings.Contains(req.Query, "query AllTraits"):
+			io.WriteString(w, `{"data": {"allTraits": [{"id": "t1", "name": "Hero"}]}}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	httpClient := &http.Client{}
+	client := NewClient("token", WithEndpoint("http://localhost"), WithHTTPClient(httpClient), WithTimeout(time.Second), WithRetries(1, time.Minute))
+
+	if client.Endpoint != "http://localhost" || client.HTTPClient != httpClient || client.Timeout != time.Second || client.Retries != 1 || client.Backoff != time.Minute {
+		t.Errorf("NewClient() ignored its options: %+v", client)
+	}
+	if client.limiter != nil {
+		t.Error("NewClient() is rate limited by default")
+	}
+
+	client = NewClient("token")
+	if client.Endpoint != DefaultEndpoint || client.Timeout != DefaultTimeout || client.Retries != DefaultRetries || client.Backoff != DefaultBackoff {
+		t.Errorf("NewClient() = %+v, want the defaults", client)
+	}
+}
+
+func TestLookups(t *testing.T) {
+	var requests int32
+	client := testClient(t, lookupsHandler(&requests))
+
+	if requests != 0 {
+		t.Fatal("NewClient() made a request")
+	}
+
+	lookups, err := client.Lookups()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Lookups{
+		Strength:     map[int]string{0: "s0", 1: "s1"},
+		Intelligence: map[int]string{2: "i2"},
+		Special:      map[int]string{},
+		Traits:       map[string]string{"Hero": "t1"},
+	}
+	if reflect.DeepEqual(lookups, want) == false {
+		t.Errorf("Lookups() = %+v, want %+v", lookups, want)
+	}
+
+	if _, err := client.Lookups(); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("Lookups() made %d requests, want 2 the first time and none after", requests)
+	}
+}
+
+func TestLookupsSeeded(t *testing.T) {
+	var requests int32
+	seeded := &Lookups{Traits: map[string]string{"Hero": "t1"}}
+	client := testClient(t, lookupsHandler(&requests), WithLookups(seeded))
+
+	lookups, err := client.Lookups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookups != seeded || requests != 0 {
+		t.Errorf("Lookups() made %d requests instead of using the seeded lookups", requests)
+	}
+}
+
+func TestLookupsError(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, req *testRequest) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	if _, err := client.Lookups(); err == nil {
+		t.Fatal("Lookups() succeeded with a 401")
+	}
+	if client.lookups != nil {
+		t.Error("Lookups() kept the lookups of a failed load")
+	}
+}
+
+func TestLookupsWrite(t *testing.T) {
+	lookups := &Lookups{
+		Strength:     map[int]string{0: "s0"},
+		Intelligence: map[int]string{},
+		Special:      map[int]string{3: "p3"},
+		Traits:       map[string]string{"Hero": "t1"},
+	}
+
+	path := filepath.Join(t.TempDir(), "lookups.json")
+	if err := lookups.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadLookups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(read, lookups) == false {
+		t.Errorf("ReadLookups() = %+v, want %+v", read, lookups)
+	}
+}
+
+func TestLookupsIDs(t *testing.T) {
+	lookups := &Lookups{
+		Strength:     map[int]string{0: "s0"},
+		Intelligence: map[int]string{2: "i2"},
+		Special:      map[int]string{1: "p1"},
+		Traits:       map[string]string{"Hero": "t1"},
+	}
+
+	card := &csv.Card{UID: "A-001", Type: "Character", Traits: []string{"Hero"}}
+	card.SetStat("strength", csv.NewStat(0))
+	card.SetStat("intelligence", csv.NewStat(2))
+	card.SetStat("special", csv.NewStat(1))
+
+	ids, err := lookups.StatIDs(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(ids, []string{"s0", "i2", "p1"}) == false {
+		t.Errorf("StatIDs() = %v", ids)
+	}
+
+	card.SetStat("special", csv.NewStat(9))
+	if _, err := lookups.StatIDs(card); err == nil {
+		t.Error("StatIDs() found a rank that isn't in the API")
+	}
+
+	if ids, err := lookups.TraitIDs(card); err != nil || reflect.DeepEqual(ids, []string{"t1"}) == false {
+		t.Errorf("TraitIDs() = %v, %v", ids, err)
+	}
+
+	card.Traits = append(card.Traits, "Villain")
+	if _, err := lookups.TraitIDs(card); err == nil {
+		t.Error("TraitIDs() found a trait that isn't in the API")
+	}
+}
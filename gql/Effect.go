@@ -1,7 +1,68 @@
 package gql
 
+import (
+	"mxdb-tools/csv"
+	"strconv"
+)
+
 type Effect struct {
 	ID     string `json:"id"`
 	Symbol string `json:"symbol"`
 	Text   string `json:"text"`
 }
+
+// Effects are the parts of a Card's rules text, in order
+type Effects []Effect
+
+// IsEqual checks if there are differences between the Effects and a csv.Card
+func (effects Effects) IsEqual(card *csv.Card) bool {
+	return len(effects.Changes(card)) == 0
+}
+
+// Changes lists the Effect properties that differ from a csv.Card, by index.
+// An Effect that only exists on one side is nil on the other
+func (effects Effects) Changes(card *csv.Card) []Change {
+	cardEffects := card.Effects()
+
+	var changes []Change
+	for i := 0; i < len(effects) || i < len(cardEffects); i++ {
+		var oldSymbol, oldText, newSymbol, newText interface{}
+		if i < len(effects) {
+			oldSymbol, oldText = effects[i].Symbol, effects[i].Text
+		}
+		if i < len(cardEffects) {
+			newSymbol, newText = cardEffects[i].Symbol, cardEffects[i].Text
+		}
+
+		prefix := strconv.Itoa(i) + "."
+		changes = appendChange(changes, prefix+"symbol", oldSymbol, newSymbol)
+		changes = appendChange(changes, prefix+"text", oldText, newText)
+	}
+
+	return changes
+}
+
+// CSV converts the Effects to the csv's effect columns
+func (effects Effects) CSV() []csv.Effect {
+	var result []csv.Effect
+	for _, effect := range effects {
+		result = append(result, csv.Effect{Symbol: effect.Symbol, Text: effect.Text})
+	}
+
+	return result
+}
+
+// effectInput is an Effect created along with a Card
+type effectInput struct {
+	Symbol string `json:"symbol"`
+	Text   string `json:"text,omitempty"`
+}
+
+func newEffectInputs(card *csv.Card) []effectInput {
+	inputs := []effectInput{}
+	for _, effect := range card.Effects() {
+		inputs = append(inputs, effectInput{Symbol: effect.Symbol, Text: effect.Text})
+	}
+
+	return inputs
+}
@@ -0,0 +1,52 @@
+package gql
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GraphQLError is a failed response from the API, with every error it returned
+type GraphQLError struct {
+	StatusCode int
+	Errors     []*ErrorDetail
+}
+
+// ErrorDetail is a single error in a graphql response
+type ErrorDetail struct {
+	Message   string        `json:"message"`
+	Path      []interface{} `json:"path,omitempty"`
+	Locations []Location    `json:"locations,omitempty"`
+}
+
+// Location is the position in the query that an error refers to
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e *GraphQLError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("Request failed: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	var messages []string
+	for _, detail := range e.Errors {
+		messages = append(messages, detail.String())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (detail *ErrorDetail) String() string {
+	if len(detail.Path) == 0 {
+		return detail.Message
+	}
+
+	var path []string
+	for _, segment := range detail.Path {
+		path = append(path, fmt.Sprint(segment))
+	}
+
+	return fmt.Sprintf("%s (at %s)", detail.Message, strings.Join(path, "."))
+}
@@ -12,10 +12,17 @@ type Image struct {
 	Medium    string `json:"medium"`
 	Small     string `json:"small"`
 	Thumbnail string `json:"thumbnail"`
+
+	Variants map[string]string `json:"variants,omitempty"`
+}
+
+// UpdateImage updates an Image
+func (client *Client) UpdateImage(image *Image, card *csv.Card) ([]byte, error) {
+	return client.send(client.UpdateImageMutation(image, card))
 }
 
-// Update updates an Image
-func (image *Image) Update(card *csv.Card) ([]byte, error) {
+// UpdateImageMutation is the Mutation sent by UpdateImage
+func (client *Client) UpdateImageMutation(image *Image, card *csv.Card) (*Mutation, error) {
 	updated := Image{
 		ID:        image.ID,
 		Original:  card.OriginalImageURL,
@@ -23,23 +30,27 @@ func (image *Image) Update(card *csv.Card) ([]byte, error) {
 		Medium:    card.MediumImageURL,
 		Small:     card.SmallImageURL,
 		Thumbnail: card.ThumbnailImageURL,
+		Variants:  card.ImageVariants,
 	}
 
-	query, err := queries.MustBytes("UpdateImage.graphql")
-	if err != nil {
-		return nil, err
-	}
-
-	return Request(query, updated)
+	return newMutation("UpdateImage.graphql", updated)
 }
 
-// IsEqual checks if there are differences between the Preview and a csv.Card
+// IsEqual checks if there are differences between the Image and a csv.Card
 func (image *Image) IsEqual(card *csv.Card) bool {
-	return (image.Original == card.OriginalImageURL &&
-		image.Large == card.LargeImageURL &&
-		image.Medium == card.MediumImageURL &&
-		image.Small == card.SmallImageURL &&
-		image.Thumbnail == card.ThumbnailImageURL)
+	return len(image.Changes(card)) == 0
+}
+
+// Changes lists the Image properties that differ from a csv.Card
+func (image *Image) Changes(card *csv.Card) []Change {
+	var changes []Change
+	changes = appendChange(changes, "original", image.Original, card.OriginalImageURL)
+	changes = appendChange(changes, "large", image.Large, card.LargeImageURL)
+	changes = appendChange(changes, "medium", image.Medium, card.MediumImageURL)
+	changes = appendChange(changes, "small", image.Small, card.SmallImageURL)
+	changes = appendChange(changes, "thumbnail", image.Thumbnail, card.ThumbnailImageURL)
+	changes = appendChange(changes, "variants", variants(image.Variants), variants(card.ImageVariants))
+	return changes
 }
 
 func (image *Image) IsEmpty() bool {
@@ -49,3 +60,12 @@ func (image *Image) IsEmpty() bool {
 		image.Small == "" &&
 		image.Thumbnail == "")
 }
+
+// variants treats empty and missing variants as the same
+func variants(v map[string]string) map[string]string {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return v
+}
@@ -0,0 +1,155 @@
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mxdb-tools/csv"
+)
+
+// Lookups maps stat ranks and trait names to their IDs in the API
+type Lookups struct {
+	Strength     map[int]string    `json:"strength"`
+	Intelligence map[int]string    `json:"intelligence"`
+	Special      map[int]string    `json:"special"`
+	Traits       map[string]string `json:"traits"`
+}
+
+// ReadLookups loads Lookups previously saved with Write
+func ReadLookups(path string) (*Lookups, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lookups := &Lookups{}
+	if err := json.Unmarshal(body, lookups); err != nil {
+		return nil, err
+	}
+
+	return lookups, nil
+}
+
+// Write saves the Lookups to a file so they can be seeded without the API
+func (lookups *Lookups) Write(path string) error {
+	body, err := json.MarshalIndent(lookups, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, body, 0600)
+}
+
+// StatIDs returns the IDs of the card's stat ranks. It is an error for the card
+// to be missing stats its type needs, to have stats its type can't, or to have
+// a rank that doesn't exist in the API
+func (lookups *Lookups) StatIDs(card *csv.Card) ([]string, error) {
+	if err := card.CheckStats(); err != nil {
+		return nil, fmt.Errorf("Invalid stats for %s: %s", card.UID, err)
+	}
+
+	var ids []string
+	for _, name := range csv.StatNames {
+		if err := card.CheckStat(name); err != nil {
+			return nil, fmt.Errorf("Invalid %s for %s: %s", name, card.UID, err)
+		}
+
+		stat := card.Stat(name)
+		if stat.Valid == false {
+			continue
+		}
+
+		id := lookups.StatID(name, stat.Rank)
+		if id == "" {
+			return nil, fmt.Errorf("No %s stat with rank %d for %s", name, stat.Rank, card.UID)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// StatID returns the ID of a stat rank, or an empty string if it doesn't exist in the API
+func (lookups *Lookups) StatID(name string, rank int) string {
+	switch name {
+	case "strength":
+		return lookups.Strength[rank]
+	case "intelligence":
+		return lookups.Intelligence[rank]
+	case "special":
+		return lookups.Special[rank]
+	}
+
+	return ""
+}
+
+// TraitIDs returns the IDs of the card's traits. It is an error for a trait
+// not to exist in the API
+func (lookups *Lookups) TraitIDs(card *csv.Card) ([]string, error) {
+	var ids []string
+	for _, name := range card.Traits {
+		id := lookups.Traits[name]
+		if id == "" {
+			return nil, fmt.Errorf("No trait named %q for %s", name, card.UID)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Lookups returns the Client's Lookups, loading them from the API on first use
+func (client *Client) Lookups() (*Lookups, error) {
+	if client.lookups != nil {
+		return client.lookups, nil
+	}
+
+	return client.LoadLookups()
+}
+
+// SetLookups seeds the Client's Lookups, e.g. from a file read with ReadLookups
+func (client *Client) SetLookups(lookups *Lookups) {
+	client.lookups = lookups
+}
+
+// LoadLookups fetches the stat ranks and traits from the API
+func (client *Client) LoadLookups() (*Lookups, error) {
+	statRanks, err := client.FetchStatsByType()
+	if err != nil {
+		return nil, err
+	}
+
+	traits, err := client.FetchTraits()
+	if err != nil {
+		return nil, err
+	}
+
+	client.lookups = NewLookups(statRanks, traits)
+	return client.lookups, nil
+}
+
+// NewLookups maps the stat ranks and traits from the API to their IDs
+func NewLookups(statRanks *StatRanks, traits []*Trait) *Lookups {
+	lookups := &Lookups{
+		Strength:     make(map[int]string),
+		Intelligence: make(map[int]string),
+		Special:      make(map[int]string),
+		Traits:       make(map[string]string),
+	}
+
+	for _, stat := range statRanks.Strength {
+		lookups.Strength[stat.Rank] = stat.ID
+	}
+	for _, stat := range statRanks.Intelligence {
+		lookups.Intelligence[stat.Rank] = stat.ID
+	}
+	for _, stat := range statRanks.Special {
+		lookups.Special[stat.Rank] = stat.ID
+	}
+
+	for _, trait := range traits {
+		lookups.Traits[trait.Name] = trait.ID
+	}
+
+	return lookups
+}
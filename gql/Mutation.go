@@ -0,0 +1,33 @@
+package gql
+
+import "strings"
+
+// Mutation is a graphql mutation with its variables. It can be sent on its own
+// or along with others in a Batch
+type Mutation struct {
+	Name      string
+	Query     []byte
+	Variables interface{}
+}
+
+func newMutation(queryFilename string, variables interface{}) (*Mutation, error) {
+	query, err := queries.MustBytes(queryFilename)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mutation{
+		Name:      strings.TrimSuffix(queryFilename, ".graphql"),
+		Query:     query,
+		Variables: variables,
+	}, nil
+}
+
+// send makes the request for a Mutation as soon as it is built
+func (client *Client) send(m *Mutation, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Mutate(m.Query, m.Variables)
+}
@@ -1,6 +1,9 @@
 package gql
 
-import "mxdb-tools/csv"
+import (
+	"errors"
+	"mxdb-tools/csv"
+)
 
 // Preview represents the Preview object on the Graphql server
 type Preview struct {
@@ -10,8 +13,13 @@ type Preview struct {
 	IsActive   bool   `json:"isActive,omitempty"`
 }
 
-// Update updates a Preview
-func (preview *Preview) Update(card *csv.Card) ([]byte, error) {
+// UpdatePreview updates a Preview
+func (client *Client) UpdatePreview(preview *Preview, card *csv.Card) ([]byte, error) {
+	return client.send(client.UpdatePreviewMutation(preview, card))
+}
+
+// UpdatePreviewMutation is the Mutation sent by UpdatePreview
+func (client *Client) UpdatePreviewMutation(preview *Preview, card *csv.Card) (*Mutation, error) {
 	updated := Preview{
 		ID:         preview.ID,
 		Previewer:  card.Previewer,
@@ -19,17 +27,28 @@ func (preview *Preview) Update(card *csv.Card) ([]byte, error) {
 		IsActive:   card.PreviewActive,
 	}
 
-	query, err := queries.MustBytes("UpdatePreview.graphql")
-	if err != nil {
-		return nil, err
+	return newMutation("UpdatePreview.graphql", updated)
+}
+
+// DeactivatePreview marks a Preview as no longer active
+func (client *Client) DeactivatePreview(preview *Preview) ([]byte, error) {
+	if preview.ID == "" {
+		return nil, errors.New("No preview to deactivate")
 	}
 
-	return Request(query, updated)
+	return client.send(newMutation("DeactivatePreview.graphql", Preview{ID: preview.ID}))
 }
 
 // IsEqual checks if there are differences between the Preview and a csv.Card
 func (preview *Preview) IsEqual(card *csv.Card) bool {
-	return (preview.Previewer == card.Previewer &&
-		preview.PreviewURL == card.PreviewURL &&
-		preview.IsActive == card.PreviewActive)
+	return len(preview.Changes(card)) == 0
+}
+
+// Changes lists the Preview properties that differ from a csv.Card
+func (preview *Preview) Changes(card *csv.Card) []Change {
+	var changes []Change
+	changes = appendChange(changes, "previewer", preview.Previewer, card.Previewer)
+	changes = appendChange(changes, "previewUrl", preview.PreviewURL, card.PreviewURL)
+	changes = appendChange(changes, "isActive", preview.IsActive, card.PreviewActive)
+	return changes
 }
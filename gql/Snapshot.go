@@ -0,0 +1,129 @@
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mxdb-tools/fs"
+	"time"
+)
+
+// SnapshotVersion is the format of the Snapshots written by this version. Version 1,
+// from before cards had several traits and effects, can still be read
+const SnapshotVersion = 2
+
+// Snapshot is a copy of every card, trait and stat rank in the API at one point in time
+type Snapshot struct {
+	Version   int        `json:"version"`
+	Endpoint  string     `json:"endpoint"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Cards     []*Card    `json:"cards"`
+	Traits    []*Trait   `json:"traits"`
+	StatRanks *StatRanks `json:"statRanks"`
+}
+
+// FetchSnapshot fetches everything in the API that a sync compares against
+func (client *Client) FetchSnapshot() (*Snapshot, error) {
+	cards, err := client.FetchCards()
+	if err != nil {
+		return nil, err
+	}
+
+	traits, err := client.FetchTraits()
+	if err != nil {
+		return nil, err
+	}
+
+	statRanks, err := client.FetchStatsByType()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{
+		Version:   SnapshotVersion,
+		Endpoint:  client.Endpoint,
+		CreatedAt: time.Now().UTC(),
+		Cards:     cards,
+		Traits:    traits,
+		StatRanks: statRanks,
+	}, nil
+}
+
+// Lookups maps the Snapshot's traits and stat ranks to their IDs, like the
+// Lookups of a Client connected to the API at the time
+func (snapshot *Snapshot) Lookups() *Lookups {
+	return NewLookups(snapshot.StatRanks, snapshot.Traits)
+}
+
+// ReadSnapshot loads a Snapshot saved with Write
+func ReadSnapshot(path string) (*Snapshot, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(body, snapshot); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	switch snapshot.Version {
+	case SnapshotVersion:
+	case 1:
+		if err := upgradeSnapshotV1(snapshot, body); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: Unsupported snapshot version %d", path, snapshot.Version)
+	}
+	if snapshot.StatRanks == nil {
+		snapshot.StatRanks = &StatRanks{}
+	}
+
+	return snapshot, nil
+}
+
+// upgradeSnapshotV1 reads the single trait and effect that cards had in version 1
+func upgradeSnapshotV1(snapshot *Snapshot, body []byte) error {
+	type cardV1 struct {
+		Trait  Trait  `json:"trait"`
+		Effect Effect `json:"effect"`
+	}
+	type snapshotV1 struct {
+		Cards []*cardV1 `json:"cards"`
+	}
+
+	v1 := &snapshotV1{}
+	if err := json.Unmarshal(body, v1); err != nil {
+		return err
+	}
+
+	for i, card := range v1.Cards {
+		if i >= len(snapshot.Cards) {
+			break
+		}
+		if card.Trait.ID != "" || card.Trait.Name != "" {
+			snapshot.Cards[i].Traits = []Trait{card.Trait}
+		}
+		if card.Effect.ID != "" || card.Effect.Symbol != "" || card.Effect.Text != "" {
+			snapshot.Cards[i].Effects = Effects{card.Effect}
+		}
+	}
+
+	snapshot.Version = SnapshotVersion
+	return nil
+}
+
+// Write saves the Snapshot. The file is replaced only once it is complete
+func (snapshot *Snapshot) Write(path string) error {
+	body, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return fs.WriteAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(body)
+		return err
+	})
+}
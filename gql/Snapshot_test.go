@@ -0,0 +1,105 @@
+package gql
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	snapshot := &Snapshot{
+		Version:  SnapshotVersion,
+		Endpoint: "https://api.example.com",
+		Cards: []*Card{
+			{UID: "1-001", Traits: []Trait{{ID: "t1", Name: "Hero"}}, Effects: Effects{{ID: "e1", Symbol: "const", Text: "Draw"}}},
+		},
+		Traits:    []*Trait{{ID: "t1", Name: "Hero"}},
+		StatRanks: &StatRanks{Strength: []*Stats{{ID: "s3", Type: "STRENGTH", Rank: 3}}},
+	}
+
+	if err := snapshot.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(read, snapshot) == false {
+		t.Errorf("ReadSnapshot = %+v, want %+v", read, snapshot)
+	}
+
+	lookups := read.Lookups()
+	if lookups.Traits["Hero"] != "t1" || lookups.StatID("strength", 3) != "s3" {
+		t.Errorf("Lookups = %+v, want the snapshot's trait and stat rank", lookups)
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantTraits  []Trait
+		wantEffects Effects
+		wantErr     bool
+	}{
+		{
+			name:        "version 2",
+			body:        `{"version": 2, "cards": [{"uid": "1-001", "traits": [{"id": "t1", "name": "Hero"}], "effects": [{"id": "e1", "text": "Draw"}]}]}`,
+			wantTraits:  []Trait{{ID: "t1", Name: "Hero"}},
+			wantEffects: Effects{{ID: "e1", Text: "Draw"}},
+		},
+		{
+			name:        "version 1",
+			body:        `{"version": 1, "cards": [{"uid": "1-001", "trait": {"id": "t1", "name": "Hero"}, "effect": {"id": "e1", "text": "Draw"}}]}`,
+			wantTraits:  []Trait{{ID: "t1", Name: "Hero"}},
+			wantEffects: Effects{{ID: "e1", Text: "Draw"}},
+		},
+		{
+			name: "version 1 without a trait or effect",
+			body: `{"version": 1, "cards": [{"uid": "1-001", "trait": null, "effect": null}]}`,
+		},
+		{
+			name:    "unknown version",
+			body:    `{"version": 3, "cards": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			body:    `{"version": `,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "snapshot.json")
+			if err := ioutil.WriteFile(path, []byte(test.body), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot, err := ReadSnapshot(path)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("ReadSnapshot error = %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if snapshot.Version != SnapshotVersion {
+				t.Errorf("Version = %d, want %d", snapshot.Version, SnapshotVersion)
+			}
+			if snapshot.StatRanks == nil {
+				t.Error("StatRanks = nil, want empty StatRanks")
+			}
+			card := snapshot.Cards[0]
+			if reflect.DeepEqual(card.Traits, test.wantTraits) == false {
+				t.Errorf("Traits = %+v, want %+v", card.Traits, test.wantTraits)
+			}
+			if reflect.DeepEqual(card.Effects, test.wantEffects) == false {
+				t.Errorf("Effects = %+v, want %+v", card.Effects, test.wantEffects)
+			}
+		})
+	}
+}
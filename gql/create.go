@@ -5,98 +5,61 @@ import (
 	"mxdb-tools/csv"
 )
 
-func CreateCard(card *csv.Card) ([]byte, error) {
-	if card.Type == "Character" {
-		return CreateCharacterCard(card)
-	}
-
-	if card.Type == "Event" {
-		return CreateEventCard(card)
-	}
-
-	if card.Type == "Battle" {
-		return CreateBattleCard(card)
-	}
-
-	return nil, fmt.Errorf("Invalid card type: %s", card.Type)
-}
-
-func CreateCharacterCard(card *csv.Card) ([]byte, error) {
-	var queryFilename string
-	if card.HasPreview() {
-		queryFilename = "CreateCharacterCardWithPreview.graphql"
-	} else {
-		queryFilename = "CreateCharacterCard.graphql"
-	}
-
-	query, err := queries.MustBytes(queryFilename)
-	if err != nil {
-		return nil, err
-	}
-
-	return Request(query, prepareCard(card))
+// CreateCard creates a Card with its Effects, Image and Preview
+func (client *Client) CreateCard(card *csv.Card) ([]byte, error) {
+	return client.send(client.CreateCardMutation(card))
 }
 
-func CreateEventCard(card *csv.Card) ([]byte, error) {
-	var queryFilename string
-	if card.HasPreview() {
-		queryFilename = "CreateEventCardWithPreview.graphql"
-	} else {
-		queryFilename = "CreateEventCard.graphql"
-	}
-
-	query, err := queries.MustBytes(queryFilename)
-	if err != nil {
-		return nil, err
+// CreateCardMutation is the Mutation sent by CreateCard, which depends on the card type
+func (client *Client) CreateCardMutation(card *csv.Card) (*Mutation, error) {
+	if card.Type != "Character" && card.Type != "Event" && card.Type != "Battle" {
+		return nil, fmt.Errorf("Invalid card type: %s", card.Type)
 	}
 
-	return Request(query, prepareCard(card))
-}
-
-func CreateBattleCard(card *csv.Card) ([]byte, error) {
 	var queryFilename string
 	if card.HasPreview() {
-		queryFilename = "CreateBattleCardWithPreview.graphql"
+		queryFilename = "Create" + card.Type + "CardWithPreview.graphql"
 	} else {
-		queryFilename = "CreateBattleCard.graphql"
+		queryFilename = "Create" + card.Type + "Card.graphql"
 	}
 
-	query, err := queries.MustBytes(queryFilename)
+	prepared, err := client.prepareCard(card)
 	if err != nil {
 		return nil, err
 	}
 
-	return Request(query, prepareCard(card))
+	return newMutation(queryFilename, prepared)
 }
 
 /* Create utils */
 
 type preparedCard struct {
 	*csv.Card
-	StatIDs []string `json:"statsIds,omitempty"`
-	TraitID string   `json:"traitId,omitempty"`
+	StatIDs  []string      `json:"statsIds,omitempty"`
+	TraitIDs []string      `json:"traitsIds,omitempty"`
+	Effects  []effectInput `json:"effects"`
 }
 
-func prepareCard(card *csv.Card) preparedCard {
-	var statIDs []string
-	if id := strengthIDs[card.Strength]; id != "" {
-		statIDs = append(statIDs, id)
-	}
-	if id := intelligenceIDs[card.Intelligence]; id != "" {
-		statIDs = append(statIDs, id)
+func (client *Client) prepareCard(card *csv.Card) (preparedCard, error) {
+	lookups, err := client.Lookups()
+	if err != nil {
+		return preparedCard{}, err
 	}
-	if id := specialIDs[card.Special]; id != "" {
-		statIDs = append(statIDs, id)
+
+	statIDs, err := lookups.StatIDs(card)
+	if err != nil {
+		return preparedCard{}, err
 	}
 
-	var traitID string
-	if id := traitIDs[card.Trait]; id != "" {
-		traitID = id
+	traitIDs, err := lookups.TraitIDs(card)
+	if err != nil {
+		return preparedCard{}, err
 	}
 
 	return preparedCard{
-		Card:    card,
-		StatIDs: statIDs,
-		TraitID: traitID,
-	}
+		Card:     card,
+		StatIDs:  statIDs,
+		TraitIDs: traitIDs,
+		Effects:  newEffectInputs(card),
+	}, nil
 }
@@ -0,0 +1,68 @@
+package gql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeftBehindError is returned by DeleteCard when the Card was deleted, but
+// some of its other nodes weren't
+type LeftBehindError struct {
+	Nodes []string
+}
+
+func (e *LeftBehindError) Error() string {
+	return "Card deleted, but not its other nodes: " + strings.Join(e.Nodes, "; ")
+}
+
+// DeleteCard deletes a Card, then its Effects, Image and Preview. The Card goes
+// first so a failure never leaves part of it in the API. Nodes that couldn't be
+// deleted after it are listed in a LeftBehindError
+func (client *Client) DeleteCard(c *Card) ([]byte, error) {
+	body, err := client.deleteNode("DeleteCard.graphql", c.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	type node struct {
+		queryFilename string
+		id            string
+	}
+
+	var nodes []node
+	for _, effect := range c.Effects {
+		nodes = append(nodes, node{"DeleteEffect.graphql", effect.ID})
+	}
+	if c.Image.ID != "" {
+		nodes = append(nodes, node{"DeleteImage.graphql", c.Image.ID})
+	}
+	if c.Preview.ID != "" {
+		nodes = append(nodes, node{"DeletePreview.graphql", c.Preview.ID})
+	}
+
+	var left []string
+	for _, n := range nodes {
+		if _, err := client.deleteNode(n.queryFilename, n.id); err != nil {
+			left = append(left, fmt.Sprintf("%s %s: %s", strings.TrimSuffix(n.queryFilename, ".graphql"), n.id, err))
+		}
+	}
+	if len(left) != 0 {
+		return body, &LeftBehindError{Nodes: left}
+	}
+
+	return body, nil
+}
+
+/* Delete utils */
+
+func (client *Client) deleteNode(queryFilename string, id string) ([]byte, error) {
+	return client.send(deleteMutation(queryFilename, id))
+}
+
+func deleteMutation(queryFilename string, id string) (*Mutation, error) {
+	type deleted struct {
+		ID string `json:"id"`
+	}
+
+	return newMutation(queryFilename, deleted{ID: id})
+}
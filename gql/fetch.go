@@ -2,10 +2,11 @@ package gql
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 // FetchCards fetches all cards from the API
-func FetchCards() ([]*Card, error) {
+func (client *Client) FetchCards() ([]*Card, error) {
 	type allCards struct {
 		AllCards []*Card `json:"allCards"`
 	}
@@ -16,7 +17,7 @@ func FetchCards() ([]*Card, error) {
 		return nil, err
 	}
 
-	respBody, readErr := Request(query, nil)
+	respBody, readErr := client.Request(query, nil)
 	if readErr != nil {
 		return nil, readErr
 	}
@@ -30,7 +31,7 @@ func FetchCards() ([]*Card, error) {
 }
 
 // FetchTraits fetches all traits from the API
-func FetchTraits() ([]*Trait, error) {
+func (client *Client) FetchTraits() ([]*Trait, error) {
 	type allTraits struct {
 		AllTraits []*Trait `json:"allTraits"`
 	}
@@ -40,7 +41,7 @@ func FetchTraits() ([]*Trait, error) {
 		return nil, err
 	}
 
-	respBody, readErr := Request(query, nil)
+	respBody, readErr := client.Request(query, nil)
 	if readErr != nil {
 		return nil, readErr
 	}
@@ -53,14 +54,14 @@ func FetchTraits() ([]*Trait, error) {
 	return jsonResp.AllTraits, nil
 }
 
-// FetchStatRanks fetches all stats by type
-func FetchStatsByType() (*StatRanks, error) {
+// FetchStatsByType fetches all stats by type
+func (client *Client) FetchStatsByType() (*StatRanks, error) {
 	query, err := queries.MustBytes("StatRanks.graphql")
 	if err != nil {
 		return nil, err
 	}
 
-	respBody, readErr := Request(query, nil)
+	respBody, readErr := client.Request(query, nil)
 	if readErr != nil {
 		return nil, readErr
 	}
@@ -72,3 +73,40 @@ func FetchStatsByType() (*StatRanks, error) {
 
 	return jsonResp, nil
 }
+
+// FetchEnumValues fetches the allowed values of an enum, e.g. CardRarity
+func (client *Client) FetchEnumValues(name string) ([]string, error) {
+	type enumType struct {
+		Type *struct {
+			EnumValues []struct {
+				Name string `json:"name"`
+			} `json:"enumValues"`
+		} `json:"__type"`
+	}
+
+	query, err := queries.MustBytes("EnumValues.graphql")
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, readErr := client.Request(query, map[string]string{"name": name})
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	jsonResp := &enumType{}
+	if err := json.Unmarshal(respBody, jsonResp); err != nil {
+		return nil, err
+	}
+
+	if jsonResp.Type == nil {
+		return nil, fmt.Errorf("Unknown enum: %s", name)
+	}
+
+	var values []string
+	for _, value := range jsonResp.Type.EnumValues {
+		values = append(values, value.Name)
+	}
+
+	return values, nil
+}
@@ -2,42 +2,46 @@ package gql
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
-const (
-	graphqlURL = "https://api.graph.cool/simple/v1/metaxdb"
-)
-
-var token string
+// Request makes a read-only graphql query, which is safe to retry
+func (client *Client) Request(query []byte, variables interface{}) ([]byte, error) {
+	return client.request(query, variables, true)
+}
 
-// SetToken sets the token within the package to make requests
-func SetToken(t string) {
-	token = t
+// Mutate makes a graphql mutation. It is only retried when the request never
+// reached the server, since running it twice could create or delete twice
+func (client *Client) Mutate(query []byte, variables interface{}) ([]byte, error) {
+	return client.request(query, variables, false)
 }
 
-// Request makes a graphql request
-func Request(query []byte, variables interface{}) ([]byte, error) {
+func (client *Client) request(query []byte, variables interface{}, idempotent bool) ([]byte, error) {
 	reqBody, err := queryToRequest(query, variables)
 	if err != nil {
 		return nil, err
 	}
 
-	respBody, err := makeRequest(reqBody)
+	status, respBody, err := client.makeRequest(reqBody, idempotent)
 	if err != nil {
 		return nil, err
 	}
 
-	return bodyToResponse(respBody)
+	return bodyToResponse(status, respBody)
 }
 
 /* request utils */
 
-func queryToRequest(queryString []byte, variables interface{}) (*bytes.Buffer, error) {
+func queryToRequest(queryString []byte, variables interface{}) ([]byte, error) {
 	type payload struct {
 		Query     string      `json:"query"`
 		Variables interface{} `json:"variables,omitempty"`
@@ -46,54 +50,160 @@ func queryToRequest(queryString []byte, variables interface{}) (*bytes.Buffer, e
 	replacer := strings.NewReplacer("\n", "")
 	compactQuery := replacer.Replace(string(queryString))
 
-	body, err := json.Marshal(payload{
+	return json.Marshal(payload{
 		Query:     compactQuery,
 		Variables: variables,
 	})
+}
 
-	if err != nil {
-		return nil, err
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []*ErrorDetail  `json:"errors"`
+}
+
+// parseResponse decodes a response body. A failed status without a graphql
+// body is a GraphQLError with no details
+func parseResponse(status int, body []byte) (*response, error) {
+	resp := &response{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		if isSuccess(status) {
+			return nil, err
+		}
+		return nil, &GraphQLError{StatusCode: status}
+	}
+
+	if isSuccess(status) == false && len(resp.Errors) == 0 {
+		return nil, &GraphQLError{StatusCode: status}
 	}
 
-	return bytes.NewBuffer(body), nil
+	return resp, nil
 }
 
-func bodyToResponse(body []byte) ([]byte, error) {
-	type GraphqlError struct {
-		Message string `json:"message"`
+func bodyToResponse(status int, body []byte) ([]byte, error) {
+	resp, err := parseResponse(status, body)
+	if err != nil {
+		return nil, err
 	}
 
-	type GraphQlResponse struct {
-		Data   json.RawMessage `json:"data"`
-		Errors []*GraphqlError `json:"errors"`
+	if len(resp.Errors) != 0 {
+		return nil, &GraphQLError{StatusCode: status, Errors: resp.Errors}
 	}
 
-	jsonResp := GraphQlResponse{}
-	if err := json.Unmarshal(body, &jsonResp); err != nil {
-		return nil, err
+	return resp.Data, nil
+}
+
+func isSuccess(status int) bool {
+	return status >= 200 && status < 300
+}
+
+// retryableError is a failed request that may succeed if it is sent again
+type retryableError struct {
+	err error
+	// after is how long the server asked us to wait
+	after time.Duration
+	// unsent is true when the server can't have run the request, so even a
+	// mutation can be sent again
+	unsent bool
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+// makeRequest sends the body, retrying network errors, 429 and 5xx with an
+// exponential backoff. Requests that aren't idempotent are only retried when
+// they were never run. The status and body of the last response are returned
+func (client *Client) makeRequest(body []byte, idempotent bool) (int, []byte, error) {
+	var status int
+	var respBody []byte
+	var err error
+
+	wait := client.Backoff
+	for attempt := 0; attempt <= client.Retries; attempt++ {
+		if attempt > 0 {
+			if retryable := err.(retryableError); retryable.after > wait {
+				wait = retryable.after
+			}
+			log.Printf("Retrying request in %s: %s", wait, err)
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		status, respBody, err = client.doRequest(body)
+		retryable, ok := err.(retryableError)
+		if ok == false || (idempotent == false && retryable.unsent == false) {
+			break
+		}
 	}
 
-	if len(jsonResp.Errors) != 0 {
-		return nil, errors.New(jsonResp.Errors[0].Message)
+	if retryable, ok := err.(retryableError); ok {
+		err = retryable.err
 	}
 
-	return jsonResp.Data, nil
+	return status, respBody, err
 }
 
-func makeRequest(body *bytes.Buffer) ([]byte, error) {
-	req, err := http.NewRequest("POST", graphqlURL, body)
+// doRequest makes a single request, waiting for the rate limit first
+func (client *Client) doRequest(body []byte) (int, []byte, error) {
+	client.limiter.wait()
+
+	ctx := context.Background()
+	if client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", client.Endpoint, bytes.NewReader(body))
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Authorization", "Bearer "+client.Token)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return 0, nil, retryableError{err: err, unsent: isDialError(err)}
 	}
 
 	defer resp.Body.Close()
 
-	return ioutil.ReadAll(resp.Body)
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, retryableError{err: err}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		_, err := bodyToResponse(resp.StatusCode, respBody)
+		return resp.StatusCode, respBody, retryableError{
+			err:   err,
+			after: retryAfter(resp),
+			// A rate limited request with a Retry-After was turned away before it ran
+			unsent: resp.StatusCode == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "",
+		}
+	}
+
+	return resp.StatusCode, respBody, nil
+}
+
+// isDialError returns true if the request failed before a connection was made,
+// e.g. the host couldn't be resolved or refused the connection
+func isDialError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryAfter reads the Retry-After header when it is a number of seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
 }
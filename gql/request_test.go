@@ -0,0 +1,269 @@
+package gql
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingHandler fails the first failures requests with status, then succeeds
+func failingHandler(requests *int32, failures int32, status int, header http.Header) func(w http.ResponseWriter, req *testRequest) {
+	return func(w http.ResponseWriter, req *testRequest) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			for name, values := range header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(status)
+			io.WriteString(w, `{"errors": [{"message": "Try again"}]}`)
+			return
+		}
+
+		io.WriteString(w, `{"data": {"ok": true}}`)
+	}
+}
+
+func TestRequestRetries(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var requests int32
+			client := testClient(t, failingHandler(&requests, 2, status, nil))
+
+			data, err := client.Request([]byte("query { ok }"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != `{"ok": true}` {
+				t.Errorf("Request() = %s", data)
+			}
+			if requests != 3 {
+				t.Errorf("Request() made %d requests, want 3", requests)
+			}
+		})
+	}
+}
+
+func TestRequestRetriesExhausted(t *testing.T) {
+	var requests int32
+	client := testClient(t, failingHandler(&requests, 100, http.StatusServiceUnavailable, nil))
+
+	_, err := client.Request([]byte("query { ok }"), nil)
+	var gqlErr *GraphQLError
+	if errors.As(err, &gqlErr) == false || gqlErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Request() error = %#v, want a 503 GraphQLError", err)
+	}
+	if requests != DefaultRetries+1 {
+		t.Errorf("Request() made %d requests, want %d", requests, DefaultRetries+1)
+	}
+}
+
+func TestRequestClientErrorNotRetried(t *testing.T) {
+	var requests int32
+	client := testClient(t, failingHandler(&requests, 100, http.StatusBadRequest, nil))
+
+	if _, err := client.Request([]byte("query { ok }"), nil); err == nil {
+		t.Fatal("Request() succeeded with a 400")
+	}
+	if requests != 1 {
+		t.Errorf("Request() made %d requests for a 400, want 1", requests)
+	}
+}
+
+func TestMutateRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		header       http.Header
+		wantRequests int32
+	}{
+		{"server error", http.StatusInternalServerError, nil, 1},
+		{"rate limited", http.StatusTooManyRequests, nil, 1},
+		{"rate limited with Retry-After", http.StatusTooManyRequests, http.Header{"Retry-After": {"0"}}, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requests int32
+			client := testClient(t, failingHandler(&requests, 1, test.status, test.header))
+
+			client.Mutate([]byte("mutation { ok }"), nil)
+			if requests != test.wantRequests {
+				t.Errorf("Mutate() made %d requests, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRequestConnectionClosed(t *testing.T) {
+	var requests int32
+	client := testClient(t, func(w http.ResponseWriter, req *testRequest) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			conn.Close()
+			return
+		}
+
+		io.WriteString(w, `{"data": {"ok": true}}`)
+	})
+
+	if _, err := client.Request([]byte("query { ok }"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("Request() made %d requests, want 2", requests)
+	}
+
+	// The mutation reached the server, so it may have run
+	requests = 0
+	if _, err := client.Mutate([]byte("mutation { ok }"), nil); err == nil {
+		t.Fatal("Mutate() succeeded after the connection was closed")
+	}
+	if requests != 1 {
+		t.Errorf("Mutate() made %d requests, want 1", requests)
+	}
+}
+
+// countingTransport counts the requests it sends
+type countingTransport struct {
+	requests int32
+}
+
+func (transport *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&transport.requests, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestMutateConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	transport := &countingTransport{}
+	client := NewClient("token", WithEndpoint(server.URL), WithHTTPClient(&http.Client{Transport: transport}), WithRetries(2, time.Millisecond))
+
+	// A mutation that couldn't connect never ran, so it is safe to send again
+	if _, err := client.Mutate([]byte("mutation { ok }"), nil); err == nil {
+		t.Fatal("Mutate() succeeded without a server")
+	}
+	if transport.requests != 3 {
+		t.Errorf("Mutate() made %d requests, want 3", transport.requests)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	client := testClient(t, func(w http.ResponseWriter, req *testRequest) {
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, `{"data": {"ok": true}}`)
+	}, WithTimeout(20*time.Millisecond), WithRetries(0, 0))
+
+	start := time.Now()
+	if _, err := client.Request([]byte("query { ok }"), nil); err == nil {
+		t.Fatal("Request() didn't time out")
+	}
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("Request() took %s, want it to time out after 20ms", elapsed)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	if newLimiter(0) != nil {
+		t.Error("newLimiter(0) limits requests")
+	}
+
+	var requests int32
+	client := testClient(t, failingHandler(&requests, 0, 0, nil), WithRateLimit(50))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := client.Request([]byte("query { ok }"), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first request starts right away and each one after it 20ms later
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("4 requests at 50 per second took %s, want at least 60ms", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":                              0,
+		"2":                             2 * time.Second,
+		"-1":                            0,
+		"Wed, 21 Oct 2015 07:28:00 GMT": 0,
+	}
+
+	for value, want := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if value != "" {
+			resp.Header.Set("Retry-After", value)
+		}
+		if got := retryAfter(resp); got != want {
+			t.Errorf("retryAfter(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestBodyToResponse(t *testing.T) {
+	data, err := bodyToResponse(http.StatusOK, []byte(`{"data": {"card": {"id": "abc"}}}`))
+	if err != nil || string(data) != `{"card": {"id": "abc"}}` {
+		t.Errorf("bodyToResponse() = %s, %v", data, err)
+	}
+
+	if _, err := bodyToResponse(http.StatusOK, []byte("<html>")); err == nil {
+		t.Error("bodyToResponse() accepted a body that isn't JSON")
+	}
+}
+
+func TestGraphQLError(t *testing.T) {
+	body := []byte(`{"data": null, "errors": [
+		{"message": "Title is required", "path": ["m0", "title"], "locations": [{"line": 1, "column": 12}]},
+		{"message": "Not found", "path": ["m1", 0]},
+		{"message": "Syntax error"}
+	]}`)
+
+	_, err := bodyToResponse(http.StatusOK, body)
+	var gqlErr *GraphQLError
+	if errors.As(err, &gqlErr) == false {
+		t.Fatalf("bodyToResponse() error = %#v, want a GraphQLError", err)
+	}
+
+	want := &GraphQLError{
+		StatusCode: http.StatusOK,
+		Errors: []*ErrorDetail{
+			{Message: "Title is required", Path: []interface{}{"m0", "title"}, Locations: []Location{{Line: 1, Column: 12}}},
+			{Message: "Not found", Path: []interface{}{"m1", float64(0)}},
+			{Message: "Syntax error"},
+		},
+	}
+	if reflect.DeepEqual(gqlErr, want) == false {
+		t.Errorf("bodyToResponse() error = %+v, want %+v", gqlErr, want)
+	}
+
+	wantMessage := "Title is required (at m0.title); Not found (at m1.0); Syntax error"
+	if gqlErr.Error() != wantMessage {
+		t.Errorf("Error() = %q, want %q", gqlErr.Error(), wantMessage)
+	}
+}
+
+func TestGraphQLErrorWithoutDetails(t *testing.T) {
+	for _, body := range []string{"", "Bad Gateway", `{"data": null}`} {
+		_, err := bodyToResponse(http.StatusBadGateway, []byte(body))
+		var gqlErr *GraphQLError
+		if errors.As(err, &gqlErr) == false || gqlErr.StatusCode != http.StatusBadGateway {
+			t.Errorf("bodyToResponse(%q) error = %#v, want a 502 GraphQLError", body, err)
+			continue
+		}
+		if gqlErr.Error() != "Request failed: 502 Bad Gateway" {
+			t.Errorf("Error() = %q", gqlErr.Error())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package icc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io/ioutil"
+	"sort"
+)
+
+// Extract returns the ICC profile embedded in JPEG or PNG data, or nil if there isn't one
+func Extract(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte{0xFF, 0xD8}) {
+		return fromJPEG(data), nil
+	}
+
+	if bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")) {
+		return fromPNG(data)
+	}
+
+	return nil, nil
+}
+
+// fromJPEG joins the ICC_PROFILE chunks stored in APP2 segments
+func fromJPEG(data []byte) []byte {
+	marker := []byte("ICC_PROFILE\x00")
+	chunks := make(map[int][]byte)
+
+	i := 2
+	for i+4 <= len(data) {
+		if data[i] != 0xFF {
+			break
+		}
+		segment := data[i+1]
+		// Start of scan, the image data follows
+		if segment == 0xDA {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		payload := data[i+4 : i+2+length]
+		if segment == 0xE2 && bytes.HasPrefix(payload, marker) && len(payload) > len(marker)+2 {
+			sequence := int(payload[len(marker)])
+			chunks[sequence] = payload[len(marker)+2:]
+		}
+		i += 2 + length
+	}
+
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	var sequences []int
+	for sequence := range chunks {
+		sequences = append(sequences, sequence)
+	}
+	sort.Ints(sequences)
+
+	var profile []byte
+	for _, sequence := range sequences {
+		profile = append(profile, chunks[sequence]...)
+	}
+
+	return profile
+}
+
+// fromPNG inflates the profile stored in the iCCP chunk
+func fromPNG(data []byte) ([]byte, error) {
+	i := 8
+	for i+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		chunk := string(data[i+4 : i+8])
+		if i+12+length > len(data) {
+			break
+		}
+		payload := data[i+8 : i+8+length]
+
+		if chunk == "iCCP" {
+			// Profile name, null separator, then the compression method
+			nameEnd := bytes.IndexByte(payload, 0)
+			if nameEnd < 0 || nameEnd+2 > len(payload) {
+				return nil, nil
+			}
+			reader, err := zlib.NewReader(bytes.NewReader(payload[nameEnd+2:]))
+			if err != nil {
+				return nil, err
+			}
+			defer reader.Close()
+			return ioutil.ReadAll(reader)
+		}
+		if chunk == "IDAT" {
+			break
+		}
+		i += 12 + length
+	}
+
+	return nil, nil
+}
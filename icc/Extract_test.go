@@ -0,0 +1,80 @@
+package icc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"testing"
+)
+
+func pngChunk(name string, payload []byte) []byte {
+	chunk := make([]byte, 8)
+	binary.BigEndian.PutUint32(chunk[0:4], uint32(len(payload)))
+	copy(chunk[4:8], name)
+	chunk = append(chunk, payload...)
+	// The checksum isn't read
+	return append(chunk, 0, 0, 0, 0)
+}
+
+func jpegICCSegment(sequence int, count int, data []byte) []byte {
+	payload := append([]byte("ICC_PROFILE\x00"), byte(sequence), byte(count))
+	payload = append(payload, data...)
+
+	segment := []byte{0xFF, 0xE2, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:4], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func TestExtract(t *testing.T) {
+	profile := []byte("profile data")
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	writer.Write(profile)
+	writer.Close()
+
+	png := []byte("\x89PNG\r\n\x1a\n")
+	png = append(png, pngChunk("IHDR", make([]byte, 13))...)
+	png = append(png, pngChunk("iCCP", append([]byte("ICC\x00\x00"), compressed.Bytes()...))...)
+	png = append(png, pngChunk("IDAT", nil)...)
+
+	pngWithout := []byte("\x89PNG\r\n\x1a\n")
+	pngWithout = append(pngWithout, pngChunk("IHDR", make([]byte, 13))...)
+	pngWithout = append(pngWithout, pngChunk("IDAT", nil)...)
+
+	// The chunks are out of order, and joined by their sequence number
+	jpeg := []byte{0xFF, 0xD8}
+	jpeg = append(jpeg, jpegICCSegment(2, 2, profile[7:])...)
+	jpeg = append(jpeg, jpegICCSegment(1, 2, profile[:7])...)
+	jpeg = append(jpeg, 0xFF, 0xDA, 0, 2)
+
+	jpegWithout := []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2}
+
+	truncated := append([]byte{0xFF, 0xD8}, jpegICCSegment(1, 1, profile)...)
+	truncated = truncated[:len(truncated)-4]
+
+	tests := []struct {
+		name string
+		data []byte
+		want []byte
+	}{
+		{"PNG", png, profile},
+		{"PNG without a profile", pngWithout, nil},
+		{"JPEG", jpeg, profile},
+		{"JPEG without a profile", jpegWithout, nil},
+		{"truncated JPEG", truncated, nil},
+		{"GIF", []byte("GIF89a"), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Extract(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Equal(got, test.want) == false {
+				t.Errorf("Extract = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package icc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Lut is a lut8Type or lut16Type table, such as the A2B0 tag of a CMYK profile,
+// which converts device values to the profile connection space. Every table
+// holds values from 0-1
+type Lut struct {
+	Inputs  int
+	Outputs int
+	Grid    int
+	// Is16 is true for a lut16Type, which encodes Lab differently from a lut8Type
+	Is16 bool
+
+	inputCurves  [][]float64
+	clut         []float64
+	outputCurves [][]float64
+}
+
+// parseLut reads a lut8Type (mft1) or lut16Type (mft2) tag. Other tag types,
+// like the lutAtoBType of v4 profiles, aren't supported and return no Lut
+func parseLut(tag []byte) (*Lut, error) {
+	if len(tag) < 4 {
+		return nil, nil
+	}
+
+	var inputEntries, outputEntries, width, offset int
+	switch string(tag[0:4]) {
+	case "mft1":
+		if len(tag) < 48 {
+			return nil, errors.New("Truncated ICC lut8Type")
+		}
+		inputEntries, outputEntries, width, offset = 256, 256, 1, 48
+	case "mft2":
+		if len(tag) < 52 {
+			return nil, errors.New("Truncated ICC lut16Type")
+		}
+		inputEntries = int(binary.BigEndian.Uint16(tag[48:50]))
+		outputEntries = int(binary.BigEndian.Uint16(tag[50:52]))
+		width, offset = 2, 52
+	default:
+		return nil, nil
+	}
+
+	lut := &Lut{
+		Inputs:  int(tag[8]),
+		Outputs: int(tag[9]),
+		Grid:    int(tag[10]),
+		Is16:    width == 2,
+	}
+	if lut.Inputs < 1 || lut.Inputs > 8 || lut.Outputs != 3 || lut.Grid < 2 {
+		return nil, fmt.Errorf("Invalid ICC lut with %d inputs, %d outputs and %d grid points", lut.Inputs, lut.Outputs, lut.Grid)
+	}
+	if inputEntries < 2 || outputEntries < 2 {
+		return nil, errors.New("Invalid ICC lut curves")
+	}
+
+	// Grid^Inputs can overflow, so the table is checked against the values left
+	// in the tag as it grows
+	remaining := (len(tag) - offset) / width
+	remaining -= lut.Inputs*inputEntries + lut.Outputs*outputEntries
+	clutSize := lut.Outputs
+	for i := 0; i < lut.Inputs; i++ {
+		if clutSize > remaining/lut.Grid {
+			return nil, errors.New("Truncated ICC lut")
+		}
+		clutSize *= lut.Grid
+	}
+
+	read := func(n int) []float64 {
+		values := make([]float64, n)
+		for i := range values {
+			if width == 1 {
+				values[i] = float64(tag[offset]) / 255
+			} else {
+				values[i] = float64(binary.BigEndian.Uint16(tag[offset:])) / 65535
+			}
+			offset += width
+		}
+		return values
+	}
+
+	for i := 0; i < lut.Inputs; i++ {
+		lut.inputCurves = append(lut.inputCurves, read(inputEntries))
+	}
+	lut.clut = read(clutSize)
+	for i := 0; i < lut.Outputs; i++ {
+		lut.outputCurves = append(lut.outputCurves, read(outputEntries))
+	}
+
+	return lut, nil
+}
+
+// Eval converts the device values in from 0-1 to the three connection space values
+func (lut *Lut) Eval(in []float64) [3]float64 {
+	grid := float64(lut.Grid - 1)
+
+	// The grid cell the values fall in, and how far into it they are
+	base := make([]int, lut.Inputs)
+	fraction := make([]float64, lut.Inputs)
+	for i := 0; i < lut.Inputs; i++ {
+		position := interpolate(lut.inputCurves[i], in[i]) * grid
+		base[i] = int(position)
+		if base[i] >= lut.Grid-1 {
+			base[i] = lut.Grid - 2
+		}
+		fraction[i] = position - float64(base[i])
+	}
+
+	// Multilinear interpolation between the 2^Inputs corners of the cell
+	var out [3]float64
+	for corner := 0; corner < 1<<uint(lut.Inputs); corner++ {
+		weight := 1.0
+		index := 0
+		for i := 0; i < lut.Inputs; i++ {
+			step := (corner >> uint(lut.Inputs-1-i)) & 1
+			if step == 1 {
+				weight *= fraction[i]
+			} else {
+				weight *= 1 - fraction[i]
+			}
+			index = index*lut.Grid + base[i] + step
+		}
+		if weight == 0 {
+			continue
+		}
+
+		for o := 0; o < 3; o++ {
+			out[o] += weight * lut.clut[index*lut.Outputs+o]
+		}
+	}
+
+	for o := 0; o < 3; o++ {
+		out[o] = interpolate(lut.outputCurves[o], out[o])
+	}
+
+	return out
+}
+
+// interpolate looks up v from 0-1 in an evenly spaced table
+func interpolate(table []float64, v float64) float64 {
+	v = math.Max(0, math.Min(1, v))
+	position := v * float64(len(table)-1)
+	i := int(position)
+	if i >= len(table)-1 {
+		return table[len(table)-1]
+	}
+
+	return table[i] + (table[i+1]-table[i])*(position-float64(i))
+}
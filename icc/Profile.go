@@ -0,0 +1,261 @@
+package icc
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// Profile is the part of an ICC color profile needed to convert to sRGB
+type Profile struct {
+	ColorSpace  string
+	Description string
+	// PCS is the connection space, "XYZ" or "Lab"
+	PCS string
+
+	// Matrix converts linear RGB to the D50 XYZ connection space
+	Matrix [3][3]float64
+	// Curves linearize each of the R, G and B channels
+	Curves [3]Curve
+	// Gray linearizes the channel of a GRAY profile
+	Gray Curve
+	// Lut is the A2B0 table that converts any other color space, e.g. CMYK
+	Lut *Lut
+
+	hasMatrix bool
+}
+
+// Curve maps an encoded channel value from 0-1 to its linear value
+type Curve func(float64) float64
+
+// Parse reads the header, the RGB matrix/TRC or gray TRC tags and the A2B0
+// table of an ICC profile
+func Parse(data []byte) (*Profile, error) {
+	if len(data) < 132 || string(data[36:40]) != "acsp" {
+		return nil, errors.New("Invalid ICC profile")
+	}
+
+	profile := &Profile{
+		ColorSpace: strings.TrimSpace(string(data[16:20])),
+		PCS:        strings.TrimSpace(string(data[20:24])),
+	}
+
+	tags := make(map[string][]byte)
+	count := int(binary.BigEndian.Uint32(data[128:132]))
+	for i := 0; i < count; i++ {
+		entry := 132 + (i * 12)
+		if entry+12 > len(data) {
+			return nil, errors.New("Truncated ICC tag table")
+		}
+		signature := string(data[entry : entry+4])
+		offset := int(binary.BigEndian.Uint32(data[entry+4 : entry+8]))
+		size := int(binary.BigEndian.Uint32(data[entry+8 : entry+12]))
+		if offset < 0 || size < 0 || offset+size > len(data) {
+			return nil, errors.New("Invalid ICC tag: " + signature)
+		}
+		tags[signature] = data[offset : offset+size]
+	}
+
+	profile.Description = parseText(tags["desc"])
+
+	lut, err := parseLut(tags["A2B0"])
+	if err != nil {
+		return nil, err
+	}
+	profile.Lut = lut
+
+	if profile.ColorSpace == "GRAY" {
+		if curve, ok := parseCurve(tags["kTRC"]); ok {
+			profile.Gray = curve
+		}
+		return profile, nil
+	}
+
+	if profile.ColorSpace != "RGB" {
+		return profile, nil
+	}
+
+	columns := []string{"rXYZ", "gXYZ", "bXYZ"}
+	curves := []string{"rTRC", "gTRC", "bTRC"}
+	for i := range columns {
+		xyz, ok := parseXYZ(tags[columns[i]])
+		if ok == false {
+			return profile, nil
+		}
+		curve, ok := parseCurve(tags[curves[i]])
+		if ok == false {
+			return profile, nil
+		}
+
+		profile.Matrix[0][i] = xyz[0]
+		profile.Matrix[1][i] = xyz[1]
+		profile.Matrix[2][i] = xyz[2]
+		profile.Curves[i] = curve
+	}
+	profile.hasMatrix = true
+
+	return profile, nil
+}
+
+// IsSRGB returns true if the profile already describes sRGB
+func (profile *Profile) IsSRGB() bool {
+	if strings.Contains(strings.ToLower(profile.Description), "srgb") {
+		return true
+	}
+
+	if profile.hasMatrix == false {
+		return false
+	}
+
+	for row := range srgbToXYZ {
+		for column := range srgbToXYZ[row] {
+			if math.Abs(profile.Matrix[row][column]-srgbToXYZ[row][column]) > 0.002 {
+				return false
+			}
+		}
+	}
+
+	for _, curve := range profile.Curves {
+		for _, v := range []float64{0.1, 0.5, 0.9} {
+			if math.Abs(curve(v)-srgbDecode(v)) > 0.005 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+/* tag utils */
+
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+func parseXYZ(tag []byte) ([3]float64, bool) {
+	if len(tag) < 20 || string(tag[0:4]) != "XYZ " {
+		return [3]float64{}, false
+	}
+
+	return [3]float64{s15Fixed16(tag[8:12]), s15Fixed16(tag[12:16]), s15Fixed16(tag[16:20])}, true
+}
+
+func parseCurve(tag []byte) (Curve, bool) {
+	if len(tag) < 12 {
+		return nil, false
+	}
+
+	switch string(tag[0:4]) {
+	case "curv":
+		count := int(binary.BigEndian.Uint32(tag[8:12]))
+		if count == 0 {
+			return func(v float64) float64 { return v }, true
+		}
+		if len(tag) < 12+(count*2) {
+			return nil, false
+		}
+		if count == 1 {
+			gamma := float64(binary.BigEndian.Uint16(tag[12:14])) / 256
+			return func(v float64) float64 { return math.Pow(v, gamma) }, true
+		}
+		table := make([]float64, count)
+		for i := range table {
+			table[i] = float64(binary.BigEndian.Uint16(tag[12+(i*2):])) / 65535
+		}
+		return func(v float64) float64 {
+			position := v * float64(count-1)
+			i := int(position)
+			if i >= count-1 {
+				return table[count-1]
+			}
+			fraction := position - float64(i)
+			return table[i] + (table[i+1]-table[i])*fraction
+		}, true
+	case "para":
+		return parseParametricCurve(tag)
+	}
+
+	return nil, false
+}
+
+// parseParametricCurve reads the five ICC parametric curve functions
+func parseParametricCurve(tag []byte) (Curve, bool) {
+	function := int(binary.BigEndian.Uint16(tag[8:10]))
+	counts := []int{1, 3, 4, 5, 7}
+	if function >= len(counts) || len(tag) < 12+(counts[function]*4) {
+		return nil, false
+	}
+
+	var p [7]float64
+	for i := 0; i < counts[function]; i++ {
+		p[i] = s15Fixed16(tag[12+(i*4):])
+	}
+	g, a, b, c, d, e, f := p[0], p[1], p[2], p[3], p[4], p[5], p[6]
+
+	switch function {
+	case 0:
+		return func(x float64) float64 { return math.Pow(x, g) }, true
+	case 1:
+		return func(x float64) float64 {
+			if x >= -b/a {
+				return math.Pow(a*x+b, g)
+			}
+			return 0
+		}, true
+	case 2:
+		return func(x float64) float64 {
+			if x >= -b/a {
+				return math.Pow(a*x+b, g) + c
+			}
+			return c
+		}, true
+	case 3:
+		return func(x float64) float64 {
+			if x >= d {
+				return math.Pow(a*x+b, g)
+			}
+			return c * x
+		}, true
+	default:
+		return func(x float64) float64 {
+			if x >= d {
+				return math.Pow(a*x+b, g) + e
+			}
+			return c*x + f
+		}, true
+	}
+}
+
+// parseText reads the textDescriptionType or multiLocalizedUnicodeType of the desc tag
+func parseText(tag []byte) string {
+	if len(tag) < 12 {
+		return ""
+	}
+
+	switch string(tag[0:4]) {
+	case "desc":
+		length := int(binary.BigEndian.Uint32(tag[8:12]))
+		if 12+length > len(tag) {
+			return ""
+		}
+		return strings.TrimRight(string(tag[12:12+length]), "\x00")
+	case "mluc":
+		if len(tag) < 28 {
+			return ""
+		}
+		length := int(binary.BigEndian.Uint32(tag[20:24]))
+		offset := int(binary.BigEndian.Uint32(tag[24:28]))
+		if offset+length > len(tag) {
+			return ""
+		}
+		units := make([]uint16, length/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(tag[offset+(i*2):])
+		}
+		return string(utf16.Decode(units))
+	}
+
+	return ""
+}
@@ -0,0 +1,236 @@
+package icc
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+type testTag struct {
+	signature string
+	data      []byte
+}
+
+// testProfile builds an ICC profile with a header and the tags
+func testProfile(colorSpace string, pcs string, tags ...testTag) []byte {
+	header := make([]byte, 128)
+	copy(header[16:20], colorSpace+"    ")
+	copy(header[20:24], pcs+"    ")
+	copy(header[36:40], "acsp")
+
+	table := make([]byte, 4+len(tags)*12)
+	binary.BigEndian.PutUint32(table[0:4], uint32(len(tags)))
+
+	var body []byte
+	offset := len(header) + len(table)
+	for i, tag := range tags {
+		entry := table[4+i*12:]
+		copy(entry[0:4], tag.signature)
+		binary.BigEndian.PutUint32(entry[4:8], uint32(offset+len(body)))
+		binary.BigEndian.PutUint32(entry[8:12], uint32(len(tag.data)))
+		body = append(body, tag.data...)
+	}
+
+	profile := append(header, table...)
+	return append(profile, body...)
+}
+
+func s15Fixed16Bytes(v float64) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(int32(math.Round(v*65536))))
+	return b
+}
+
+func xyzTag(x, y, z float64) []byte {
+	tag := append([]byte("XYZ \x00\x00\x00\x00"), s15Fixed16Bytes(x)...)
+	tag = append(tag, s15Fixed16Bytes(y)...)
+	return append(tag, s15Fixed16Bytes(z)...)
+}
+
+// gammaTag is a curv tag with a single gamma
+func gammaTag(gamma float64) []byte {
+	tag := []byte("curv\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00")
+	binary.BigEndian.PutUint16(tag[12:14], uint16(gamma*256))
+	return tag
+}
+
+func descTag(text string) []byte {
+	tag := []byte("desc\x00\x00\x00\x00\x00\x00\x00\x00")
+	binary.BigEndian.PutUint32(tag[8:12], uint32(len(text)+1))
+	return append(append(tag, text...), 0)
+}
+
+// rgbProfile is an RGB profile with the sRGB primaries and the same gamma for every channel
+func rgbProfile(description string, gamma float64) []byte {
+	return testProfile("RGB", "XYZ",
+		testTag{"desc", descTag(description)},
+		testTag{"rXYZ", xyzTag(srgbToXYZ[0][0], srgbToXYZ[1][0], srgbToXYZ[2][0])},
+		testTag{"gXYZ", xyzTag(srgbToXYZ[0][1], srgbToXYZ[1][1], srgbToXYZ[2][1])},
+		testTag{"bXYZ", xyzTag(srgbToXYZ[0][2], srgbToXYZ[1][2], srgbToXYZ[2][2])},
+		testTag{"rTRC", gammaTag(gamma)},
+		testTag{"gTRC", gammaTag(gamma)},
+		testTag{"bTRC", gammaTag(gamma)},
+	)
+}
+
+// lut16Tag is an mft2 tag with linear two entry curves and a clut of grid^inputs
+// points, each set by clut from the corner's device values
+func lut16Tag(inputs int, grid int, clut func(device []float64) [3]uint16) []byte {
+	tag := make([]byte, 52)
+	copy(tag[0:4], "mft2")
+	tag[8], tag[9], tag[10] = byte(inputs), 3, byte(grid)
+	binary.BigEndian.PutUint16(tag[48:50], 2)
+	binary.BigEndian.PutUint16(tag[50:52], 2)
+
+	put := func(v uint16) {
+		tag = append(tag, byte(v>>8), byte(v))
+	}
+	linear := func() {
+		put(0)
+		put(65535)
+	}
+
+	for i := 0; i < inputs; i++ {
+		linear()
+	}
+
+	points := 1
+	for i := 0; i < inputs; i++ {
+		points *= grid
+	}
+	device := make([]float64, inputs)
+	for point := 0; point < points; point++ {
+		index := point
+		for i := inputs - 1; i >= 0; i-- {
+			device[i] = float64(index%grid) / float64(grid-1)
+			index /= grid
+		}
+		for _, v := range clut(device) {
+			put(v)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		linear()
+	}
+
+	return tag
+}
+
+// cmykToLab is a CMYK table that only uses K, with white at 0 and black at 1
+func cmykToLab(device []float64) [3]uint16 {
+	return [3]uint16{uint16(math.Round((1 - device[3]) * 0xFF00)), 0x8000, 0x8000}
+}
+
+func TestParse(t *testing.T) {
+	profile, err := Parse(rgbProfile("Linear RGB", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if profile.ColorSpace != "RGB" || profile.PCS != "XYZ" || profile.Description != "Linear RGB" {
+		t.Errorf("Parse = %s %s %q, want RGB XYZ \"Linear RGB\"", profile.ColorSpace, profile.PCS, profile.Description)
+	}
+	if profile.hasMatrix == false {
+		t.Fatal("Parse didn't read the matrix")
+	}
+	for row := range srgbToXYZ {
+		for column := range srgbToXYZ[row] {
+			if math.Abs(profile.Matrix[row][column]-srgbToXYZ[row][column]) > 0.0001 {
+				t.Errorf("Matrix[%d][%d] = %f, want %f", row, column, profile.Matrix[row][column], srgbToXYZ[row][column])
+			}
+		}
+	}
+	if v := profile.Curves[0](0.5); math.Abs(v-0.5) > 0.0001 {
+		t.Errorf("Curves[0](0.5) = %f, want 0.5", v)
+	}
+	if profile.Lut != nil {
+		t.Error("Lut is set for a profile without an A2B0 tag")
+	}
+}
+
+func TestParseLut(t *testing.T) {
+	data := testProfile("CMYK", "Lab", testTag{"A2B0", lut16Tag(4, 2, cmykToLab)})
+	profile, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lut := profile.Lut
+	if lut == nil {
+		t.Fatal("Parse didn't read the A2B0 tag")
+	}
+	if lut.Inputs != 4 || lut.Outputs != 3 || lut.Grid != 2 || lut.Is16 == false {
+		t.Errorf("Lut = %d inputs, %d outputs, %d grid points, 16 bit %v", lut.Inputs, lut.Outputs, lut.Grid, lut.Is16)
+	}
+
+	halfK := lut.Eval([]float64{0, 0, 0, 0.5})
+	if math.Abs(halfK[0]-0.5*0xFF00/65535) > 0.001 {
+		t.Errorf("Eval of 50%% K = %v, want L halfway to white", halfK)
+	}
+}
+
+func TestIsSRGB(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"sRGB description", testProfile("RGB", "XYZ", testTag{"desc", descTag("sRGB IEC61966-2.1")}), true},
+		{"sRGB primaries and curve", rgbProfile("Camera", 2.4), false},
+		{"linear", rgbProfile("Linear RGB", 1), false},
+		{"CMYK", testProfile("CMYK", "Lab", testTag{"desc", descTag("Coated FOGRA39")}), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile, err := Parse(test.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := profile.IsSRGB(); got != test.want {
+				t.Errorf("IsSRGB = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tagOutOfRange := rgbProfile("Linear RGB", 1)
+	binary.BigEndian.PutUint32(tagOutOfRange[132+4:], uint32(len(tagOutOfRange)))
+
+	truncatedTable := testProfile("RGB", "XYZ")
+	binary.BigEndian.PutUint32(truncatedTable[128:132], 10)
+
+	// 255^8 points overflows, and the tag only holds a few of them
+	overflow := lut16Tag(1, 2, func([]float64) [3]uint16 { return [3]uint16{} })
+	overflow[8], overflow[10] = 8, 255
+
+	truncatedLut := lut16Tag(4, 2, cmykToLab)
+	truncatedLut = truncatedLut[:len(truncatedLut)-8]
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, "Invalid ICC profile"},
+		{"not a profile", make([]byte, 200), "Invalid ICC profile"},
+		{"tag out of range", tagOutOfRange, "Invalid ICC tag"},
+		{"truncated tag table", truncatedTable, "Truncated ICC tag table"},
+		{"lut that overflows", testProfile("CMYK", "Lab", testTag{"A2B0", overflow}), "Truncated ICC lut"},
+		{"truncated lut", testProfile("CMYK", "Lab", testTag{"A2B0", truncatedLut}), "Truncated ICC lut"},
+		{"truncated lut header", testProfile("CMYK", "Lab", testTag{"A2B0", []byte("mft2\x00\x00\x00\x00\x04\x03\x02")}), "Truncated ICC lut16Type"},
+		{"lut with two outputs", testProfile("CMYK", "Lab", testTag{"A2B0", append([]byte("mft1\x00\x00\x00\x00\x04\x02\x02"), make([]byte, 40)...)}), "Invalid ICC lut"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.data)
+			if err == nil || strings.HasPrefix(err.Error(), test.want) == false {
+				t.Errorf("Parse error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package icc
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// srgbToXYZ converts linear sRGB to D50 XYZ, the ICC connection space
+var srgbToXYZ = [3][3]float64{
+	{0.4360747, 0.3850649, 0.1430804},
+	{0.2225045, 0.7168786, 0.0606169},
+	{0.0139322, 0.0971045, 0.7141733},
+}
+
+// xyzToSRGB is the inverse of srgbToXYZ
+var xyzToSRGB = [3][3]float64{
+	{3.1338561, -1.6168667, -0.4906146},
+	{-0.9787684, 1.9161415, 0.0334540},
+	{0.0719453, -0.2289914, 1.4052427},
+}
+
+// ToSRGB converts an image in the profile's color space to sRGB. RGB profiles
+// are converted with their matrix and curves, gray profiles with their curve and
+// any other, like CMYK, with their A2B0 table. It is an error for the profile
+// to have none of these, or for the image not to be in its color space, rather
+// than guess at the colors
+func (profile *Profile) ToSRGB(img image.Image) (*image.NRGBA, error) {
+	switch {
+	case profile.ColorSpace == "RGB" && profile.hasMatrix:
+		return profile.matrixToSRGB(img), nil
+	case profile.ColorSpace == "GRAY" && profile.Gray != nil:
+		return profile.grayToSRGB(img), nil
+	case profile.Lut != nil:
+		return profile.lutToSRGB(img)
+	}
+
+	return nil, fmt.Errorf("Unsupported %s ICC profile %q", profile.ColorSpace, profile.Description)
+}
+
+func (profile *Profile) matrixToSRGB(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	var matrix [3][3]float64
+	for row := 0; row < 3; row++ {
+		for column := 0; column < 3; column++ {
+			for i := 0; i < 3; i++ {
+				matrix[row][column] += xyzToSRGB[row][i] * profile.Matrix[i][column]
+			}
+		}
+	}
+
+	var linear [3][256]float64
+	for channel := 0; channel < 3; channel++ {
+		for v := 0; v < 256; v++ {
+			linear[channel][v] = profile.Curves[channel](float64(v) / 255)
+		}
+	}
+
+	encode := encodeTable()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb := [3]float64{linear[0][c.R], linear[1][c.G], linear[2][c.B]}
+
+			var converted [3]uint8
+			for row := 0; row < 3; row++ {
+				v := matrix[row][0]*rgb[0] + matrix[row][1]*rgb[1] + matrix[row][2]*rgb[2]
+				converted[row] = encode.lookup(v)
+			}
+
+			out.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.NRGBA{converted[0], converted[1], converted[2], c.A})
+		}
+	}
+
+	return out
+}
+
+// grayToSRGB linearizes the gray channel. The profile's white is the same as
+// sRGB's, so it becomes an sRGB gray of the same luminance
+func (profile *Profile) grayToSRGB(img image.Image) *image.NRGBA {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	encode := encodeTable()
+	var gray [256]uint8
+	for v := range gray {
+		gray[v] = encode.lookup(profile.Gray(float64(v) / 255))
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			v := gray[color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y]
+			out.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.NRGBA{v, v, v, c.A})
+		}
+	}
+
+	return out
+}
+
+// lutToSRGB converts each pixel through the A2B0 table to the connection space,
+// then to sRGB. Images with few colors are converted once per color
+func (profile *Profile) lutToSRGB(img image.Image) (*image.NRGBA, error) {
+	lut := profile.Lut
+	if profile.PCS != "Lab" && profile.PCS != "XYZ" {
+		return nil, fmt.Errorf("Unsupported ICC connection space %q in %q", profile.PCS, profile.Description)
+	}
+
+	_, isCMYK := img.(*image.CMYK)
+	switch {
+	case profile.ColorSpace == "CMYK" && lut.Inputs == 4 && isCMYK:
+	case profile.ColorSpace == "RGB" && lut.Inputs == 3 && isCMYK == false:
+	default:
+		return nil, fmt.Errorf("%s ICC profile %q doesn't match a %T", profile.ColorSpace, profile.Description, img)
+	}
+
+	bounds := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+
+	encode := encodeTable()
+	cache := make(map[[4]uint8][3]uint8)
+	in := make([]float64, lut.Inputs)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var device [4]uint8
+			alpha := uint8(255)
+			if isCMYK {
+				c := color.CMYKModel.Convert(img.At(x, y)).(color.CMYK)
+				device = [4]uint8{c.C, c.M, c.Y, c.K}
+			} else {
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				device = [4]uint8{c.R, c.G, c.B}
+				alpha = c.A
+			}
+
+			converted, ok := cache[device]
+			if ok == false {
+				for i := range in {
+					in[i] = float64(device[i]) / 255
+				}
+				rgb := multiply(xyzToSRGB, profile.pcsToXYZ(lut.Eval(in)))
+				for i := range converted {
+					converted[i] = encode.lookup(rgb[i])
+				}
+				cache[device] = converted
+			}
+
+			out.SetNRGBA(x-bounds.Min.X, y-bounds.Min.Y, color.NRGBA{converted[0], converted[1], converted[2], alpha})
+		}
+	}
+
+	return out, nil
+}
+
+// d50 is the white point of the connection space
+var d50 = [3]float64{0.9642, 1.0, 0.8249}
+
+// pcsToXYZ decodes the output of the Lut to D50 XYZ
+func (profile *Profile) pcsToXYZ(v [3]float64) [3]float64 {
+	if profile.PCS == "XYZ" {
+		// 1.15 fixed point, where 1.0 is 0x8000
+		scale := 1.0
+		if profile.Lut.Is16 {
+			scale = 65535.0 / 32768
+		}
+		return [3]float64{v[0] * scale, v[1] * scale, v[2] * scale}
+	}
+
+	// lut16Type uses the legacy Lab encoding, where 0xFF00 is the top of the range
+	scale := 1.0
+	if profile.Lut.Is16 {
+		scale = 65535.0 / 65280
+	}
+	l := v[0] * scale * 100
+	a := v[1]*scale*255 - 128
+	b := v[2]*scale*255 - 128
+
+	fy := (l + 16) / 116
+	f := [3]float64{fy + a/500, fy, fy - b/200}
+
+	var xyz [3]float64
+	for i, t := range f {
+		if t > 6.0/29 {
+			xyz[i] = t * t * t
+		} else {
+			xyz[i] = 3 * (6.0 / 29) * (6.0 / 29) * (t - 4.0/29)
+		}
+		xyz[i] *= d50[i]
+	}
+
+	return xyz
+}
+
+func multiply(matrix [3][3]float64, v [3]float64) [3]float64 {
+	var result [3]float64
+	for row := 0; row < 3; row++ {
+		result[row] = matrix[row][0]*v[0] + matrix[row][1]*v[1] + matrix[row][2]*v[2]
+	}
+
+	return result
+}
+
+// encoding maps linear sRGB values to 8 bit encoded ones
+type encoding [4096]uint8
+
+func encodeTable() *encoding {
+	encode := &encoding{}
+	for i := range encode {
+		encode[i] = uint8(math.Round(srgbEncode(float64(i)/4095) * 255))
+	}
+
+	return encode
+}
+
+func (encode *encoding) lookup(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	return encode[int(v*4095)]
+}
+
+func srgbDecode(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func srgbEncode(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
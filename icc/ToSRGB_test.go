@@ -0,0 +1,142 @@
+package icc
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func parseTestProfile(t *testing.T, data []byte) *Profile {
+	t.Helper()
+	profile, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return profile
+}
+
+func near(a, b uint8) bool {
+	return int(a)-int(b) <= 2 && int(b)-int(a) <= 2
+}
+
+func TestMatrixToSRGB(t *testing.T) {
+	profile := parseTestProfile(t, rgbProfile("Linear RGB", 1))
+
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{0, 0, 0, 255})
+	img.SetNRGBA(1, 0, color.NRGBA{255, 255, 255, 255})
+	img.SetNRGBA(2, 0, color.NRGBA{128, 128, 128, 128})
+	img.SetNRGBA(3, 0, color.NRGBA{255, 0, 0, 255})
+
+	out, err := profile.ToSRGB(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A linear 0.5 is 188 once encoded as sRGB, and the primaries are sRGB's
+	want := []color.NRGBA{{0, 0, 0, 255}, {255, 255, 255, 255}, {188, 188, 188, 128}, {255, 0, 0, 255}}
+	for x, w := range want {
+		got := out.NRGBAAt(x, 0)
+		if near(got.R, w.R) == false || near(got.G, w.G) == false || near(got.B, w.B) == false || got.A != w.A {
+			t.Errorf("pixel %d = %v, want %v", x, got, w)
+		}
+	}
+}
+
+func TestGrayToSRGB(t *testing.T) {
+	profile := parseTestProfile(t, testProfile("GRAY", "XYZ", testTag{"kTRC", gammaTag(1)}))
+
+	img := image.NewGray(image.Rect(0, 0, 2, 1))
+	img.SetGray(0, 0, color.Gray{128})
+	img.SetGray(1, 0, color.Gray{255})
+
+	out, err := profile.ToSRGB(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := out.NRGBAAt(0, 0); near(got.R, 188) == false || got.R != got.G || got.G != got.B {
+		t.Errorf("linear 50%% gray = %v, want an sRGB gray of 188", got)
+	}
+	if got := out.NRGBAAt(1, 0); got != (color.NRGBA{255, 255, 255, 255}) {
+		t.Errorf("white = %v, want white", got)
+	}
+}
+
+func TestLutToSRGB(t *testing.T) {
+	profile := parseTestProfile(t, testProfile("CMYK", "Lab", testTag{"A2B0", lut16Tag(4, 2, cmykToLab)}))
+
+	img := image.NewCMYK(image.Rect(0, 0, 3, 1))
+	img.SetCMYK(0, 0, color.CMYK{0, 0, 0, 0})
+	img.SetCMYK(1, 0, color.CMYK{0, 0, 0, 255})
+	img.SetCMYK(2, 0, color.CMYK{0, 0, 0, 128})
+
+	out, err := profile.ToSRGB(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// L* 50 is 119 in sRGB
+	want := []uint8{255, 0, 119}
+	for x, w := range want {
+		got := out.NRGBAAt(x, 0)
+		if near(got.R, w) == false || near(got.G, w) == false || near(got.B, w) == false || got.A != 255 {
+			t.Errorf("pixel %d = %v, want a gray of %d", x, got, w)
+		}
+	}
+}
+
+func TestToSRGBUnsupported(t *testing.T) {
+	cmyk := image.NewCMYK(image.Rect(0, 0, 1, 1))
+	rgb := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+
+	tests := []struct {
+		name string
+		data []byte
+		img  image.Image
+		want string
+	}{
+		{
+			name: "v4 lutAtoBType",
+			data: testProfile("CMYK", "Lab", testTag{"desc", descTag("v4 CMYK")}, testTag{"A2B0", []byte("mAB \x00\x00\x00\x00\x04\x03\x00\x00")}),
+			img:  cmyk,
+			want: "Unsupported CMYK ICC profile",
+		},
+		{
+			name: "RGB without a matrix",
+			data: testProfile("RGB", "XYZ", testTag{"rTRC", gammaTag(1)}),
+			img:  rgb,
+			want: "Unsupported RGB ICC profile",
+		},
+		{
+			name: "gray without a curve",
+			data: testProfile("GRAY", "XYZ"),
+			img:  image.NewGray(image.Rect(0, 0, 1, 1)),
+			want: "Unsupported GRAY ICC profile",
+		},
+		{
+			name: "CMYK profile for an RGB image",
+			data: testProfile("CMYK", "Lab", testTag{"A2B0", lut16Tag(4, 2, cmykToLab)}),
+			img:  rgb,
+			want: "CMYK ICC profile",
+		},
+		{
+			name: "unknown connection space",
+			data: testProfile("CMYK", "Luv", testTag{"A2B0", lut16Tag(4, 2, cmykToLab)}),
+			img:  cmyk,
+			want: "Unsupported ICC connection space",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile := parseTestProfile(t, test.data)
+			_, err := profile.ToSRGB(test.img)
+			if err == nil || strings.HasPrefix(err.Error(), test.want) == false {
+				t.Errorf("ToSRGB error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
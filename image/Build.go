@@ -0,0 +1,105 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"mxdb-tools/csv"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Summary is the outcome of building the images for many cards
+type Summary struct {
+	Total int
+	Built int
+	// Generated counts the cards whose original or renditions were rewritten
+	Generated int
+	Errors    map[string]error
+}
+
+// manifestSaveInterval is how many cards are built between saves of the Manifest
+const manifestSaveInterval = 50
+
+// Build runs CreateAll for every card across a pool of concurrent workers.
+// The Manifest is saved every manifestSaveInterval cards and once they're all
+// built. An error saving it is recorded under "manifest"
+func Build(cards []*csv.Card, concurrency int) *Summary {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	summary := &Summary{
+		Total:  len(cards),
+		Errors: make(map[string]error),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan *csv.Card)
+	finished := 0
+	var saveErr error
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for card := range jobs {
+				before := getManifest().Entry(card.UID)
+				err := CreateAll(card)
+				generated := reflect.DeepEqual(before, getManifest().Entry(card.UID)) == false
+
+				mu.Lock()
+				if err != nil {
+					summary.Errors[card.UID] = err
+				} else {
+					summary.Built++
+				}
+				if generated {
+					summary.Generated++
+				}
+				finished++
+				save := finished%manifestSaveInterval == 0
+				mu.Unlock()
+
+				if save {
+					if err := getManifest().Save(); err != nil {
+						mu.Lock()
+						saveErr = err
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+
+	for _, card := range cards {
+		jobs <- card
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(cards) != 0 {
+		saveErr = getManifest().Save()
+	}
+	if saveErr != nil {
+		summary.Errors["manifest"] = saveErr
+	}
+
+	return summary
+}
+
+// Print writes the number of cards built and every error by card UID
+func (summary *Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "Images: %d of %d built, %d generated, %d failed\n", summary.Built, summary.Total, summary.Generated, len(summary.Errors))
+
+	var uids []string
+	for uid := range summary.Errors {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	for _, uid := range uids {
+		fmt.Fprintf(w, "  %s: %s\n", uid, summary.Errors[uid])
+	}
+}
@@ -0,0 +1,109 @@
+package image
+
+import (
+	"bytes"
+	goimage "image"
+	"image/color"
+	"image/jpeg"
+	"mxdb-tools/csv"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// useTestRenditions builds a single small Rendition for the rest of the test
+func useTestRenditions(t *testing.T) {
+	previous := Renditions()
+	SetRenditions([]*Rendition{{Name: "small", Source: Original, Height: 4, Filter: "box", Format: "jpeg", Quality: 90}})
+	t.Cleanup(func() { SetRenditions(previous) })
+}
+
+func solidJPEG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+
+	img := goimage.NewRGBA(goimage.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// versionedServer serves body with an ETag, and answers 304 to a request with the current one
+type versionedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	body     []byte
+	etag     string
+	requests int
+}
+
+func newVersionedServer(body []byte, etag string) *versionedServer {
+	s := &versionedServer{body: body, etag: etag}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+
+		if r.Header.Get("If-None-Match") == s.etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("ETag", s.etag)
+		w.Write(s.body)
+	}))
+	return s
+}
+
+func (s *versionedServer) replace(body []byte, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func TestBuildRechecksOriginals(t *testing.T) {
+	useTempDirs(t)
+	useTestRenditions(t)
+
+	server := newVersionedServer(solidJPEG(t, color.White), `"v1"`)
+	defer server.Close()
+
+	card := &csv.Card{UID: "1-001", OriginalImageURL: server.URL + "/1-001.jpg"}
+
+	summary := Build([]*csv.Card{card}, 1)
+	if summary.Built != 1 || summary.Generated != 1 || len(summary.Errors) != 0 {
+		t.Fatalf("first build = %+v, want the card generated", summary)
+	}
+	if IsBuilt(card.UID) == false || IsStale(card) {
+		t.Fatal("The card isn't built after its first build")
+	}
+	first := getManifest().Entry(card.UID)
+
+	summary = Build([]*csv.Card{card}, 1)
+	if summary.Built != 1 || summary.Generated != 0 {
+		t.Errorf("unchanged build = %+v, want nothing generated", summary)
+	}
+
+	// A corrected scan at the same URL
+	server.replace(solidJPEG(t, color.Black), `"v2"`)
+	summary = Build([]*csv.Card{card}, 1)
+	if summary.Built != 1 || summary.Generated != 1 {
+		t.Errorf("changed build = %+v, want the card generated again", summary)
+	}
+
+	entry := getManifest().Entry(card.UID)
+	if entry.ETag != `"v2"` || entry.SHA256 == first.SHA256 || entry.Renditions["small"] == first.Renditions["small"] {
+		t.Errorf("Entry = %+v, want the new original and its renditions", entry)
+	}
+	if server.requests != 3 {
+		t.Errorf("%d requests, want one per build", server.requests)
+	}
+}
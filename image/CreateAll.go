@@ -1,24 +1,57 @@
 package image
 
 import (
+	"fmt"
 	"mxdb-tools/csv"
+	"sync"
 )
 
+// CreateAll creates every Rendition for a card. The original is downloaded
+// first, then each Rendition is created as soon as its source is ready
 func CreateAll(card *csv.Card) error {
 	if err := CreateOriginal(card); err != nil {
 		return err
 	}
-	if err := CreateLarge(card); err != nil {
-		return err
-	}
-	if err := CreateMedium(card); err != nil {
-		return err
+
+	done := map[string]chan struct{}{Original: make(chan struct{})}
+	close(done[Original])
+	for _, r := range renditions {
+		done[r.Name] = make(chan struct{})
 	}
-	if err := CreateSmall(card); err != nil {
-		return err
+
+	var wg sync.WaitGroup
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	for _, r := range renditions {
+		wg.Add(1)
+		go func(r *Rendition) {
+			defer wg.Done()
+			defer close(done[r.Name])
+
+			<-done[r.Source]
+
+			mu.Lock()
+			sourceErr := errs[r.Source]
+			mu.Unlock()
+
+			var err error
+			if sourceErr != nil {
+				err = fmt.Errorf("Skipped %s: %s", r.Name, sourceErr)
+			} else {
+				err = CreateRendition(card, r)
+			}
+
+			mu.Lock()
+			errs[r.Name] = err
+			mu.Unlock()
+		}(r)
 	}
-	if err := CreateThumbnail(card); err != nil {
-		return err
+	wg.Wait()
+
+	for _, r := range renditions {
+		if errs[r.Name] != nil {
+			return errs[r.Name]
+		}
 	}
 
 	return nil
@@ -0,0 +1,6 @@
+package image
+
+// CreateDirectories creates the image directories on disk
+func CreateDirectories() error {
+	return dirs.Create()
+}
@@ -2,56 +2,51 @@ package image
 
 import (
 	"errors"
-	"io"
-	"log"
+	"fmt"
 	"mxdb-tools/csv"
 	"mxdb-tools/fs"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
+// CreateOriginal downloads the card's original image. A previously downloaded
+// original is only replaced if the server reports that it has changed
 func CreateOriginal(card *csv.Card) error {
 	if card.OriginalImageURL == "" {
 		return errors.New("Missing Original Image for URL: " + card.UID)
 	}
 
 	path := filepath.Join(dirs.Original, card.Filename())
+	entry := getManifest().Entry(card.UID)
 
-	if fs.Exists(path) {
-		return nil
+	// Only ask whether the original changed if it is the one on disk
+	var validator ManifestEntry
+	if fs.Exists(path) && entry.OriginalURL == card.OriginalImageURL && entry.SHA256 != "" {
+		validator = entry
 	}
 
-	log.Println("Downloading:", card.OriginalImageURL)
-
-	resp, respErr := http.Get(card.OriginalImageURL)
-	if respErr != nil {
-		return respErr
+	result, err := download(card.OriginalImageURL, path, validator)
+	if err != nil {
+		return err
 	}
 
-	defer resp.Body.Close()
-
-	imgFile, imgErr := os.Create(path)
-	if imgErr != nil {
-		return imgErr
+	if result.NotModified {
+		return nil
 	}
 
-	defer imgFile.Close()
-
-	_, copyErr := io.Copy(imgFile, resp.Body)
-	if copyErr != nil {
-		return copyErr
+	// An original that can't be converted is removed, so the next run downloads
+	// and tries it again instead of asking whether it changed
+	if err := normalizeColor(path); err != nil {
+		os.Remove(path)
+		return fmt.Errorf("Unable to color correct %s: %s", path, err)
 	}
 
-	// TODO: This should probably check color profile instead of Card's Set
-	// TODO: Would be nice to make this cross platform
-	if card.Set == "JL" {
-		sips := exec.Command("sips", "--matchTo", "/System/Library/ColorSync/Profiles/Generic RGB Profile.icc", path)
-		if err := sips.Run(); err != nil {
-			log.Println("Unable to color correct:", path, "- Proceeding...")
-		}
-	}
+	getManifest().SetOriginal(card.UID, ManifestEntry{
+		OriginalURL:  card.OriginalImageURL,
+		ETag:         result.ETag,
+		LastModified: result.LastModified,
+		SHA256:       result.SHA256,
+	})
 
 	return nil
 }
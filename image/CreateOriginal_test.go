@@ -0,0 +1,114 @@
+package image
+
+import (
+	"bytes"
+	"encoding/binary"
+	goimage "image"
+	"image/jpeg"
+	"mxdb-tools/csv"
+	"mxdb-tools/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTempDirs saves images to a temporary directory with an empty manifest
+func useTempDirs(t *testing.T) {
+	t.Helper()
+
+	SetBaseDir(t.TempDir())
+	manifestOnce.Do(func() {})
+	manifest = &Manifest{
+		Cards: make(map[string]*ManifestEntry),
+		path:  filepath.Join(dirs.Base, "manifest.json"),
+	}
+
+	if err := CreateDirectories(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testJPEG encodes a small image, with an APP2 segment holding profile if it isn't nil
+func testJPEG(t *testing.T, profile []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, goimage.NewRGBA(goimage.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if profile == nil {
+		return data
+	}
+
+	payload := append([]byte("ICC_PROFILE\x00\x01\x01"), profile...)
+	segment := []byte{0xFF, 0xE2, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:4], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	return append(append(data[:2:2], segment...), data[2:]...)
+}
+
+// v4CMYKProfile is a CMYK profile whose A2B0 is a lutAtoBType, which isn't supported
+func v4CMYKProfile() []byte {
+	profile := make([]byte, 128)
+	copy(profile[16:24], "CMYKLab ")
+	copy(profile[36:40], "acsp")
+
+	table := make([]byte, 16)
+	binary.BigEndian.PutUint32(table[0:4], 1)
+	copy(table[4:8], "A2B0")
+	binary.BigEndian.PutUint32(table[8:12], 144)
+	binary.BigEndian.PutUint32(table[12:16], 12)
+
+	profile = append(profile, table...)
+	return append(profile, "mAB \x00\x00\x00\x00\x04\x03\x00\x00"...)
+}
+
+func serveImage(body []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(body)
+	}))
+}
+
+func TestCreateOriginal(t *testing.T) {
+	useTempDirs(t)
+	server := serveImage(testJPEG(t, nil))
+	defer server.Close()
+
+	card := &csv.Card{UID: "1-001", OriginalImageURL: server.URL + "/1-001.jpg"}
+	if err := CreateOriginal(card); err != nil {
+		t.Fatal(err)
+	}
+
+	if fs.Exists(filepath.Join(dirs.Original, card.Filename())) == false {
+		t.Error("The original wasn't saved")
+	}
+	entry := getManifest().Entry(card.UID)
+	if entry.OriginalURL != card.OriginalImageURL || entry.ETag != `"v1"` || entry.SHA256 == "" {
+		t.Errorf("Entry = %+v, want the downloaded original", entry)
+	}
+}
+
+func TestCreateOriginalUnsupportedProfile(t *testing.T) {
+	useTempDirs(t)
+	server := serveImage(testJPEG(t, v4CMYKProfile()))
+	defer server.Close()
+
+	card := &csv.Card{UID: "1-001", OriginalImageURL: server.URL + "/1-001.jpg"}
+	err := CreateOriginal(card)
+	if err == nil || strings.Contains(err.Error(), "Unsupported CMYK ICC profile") == false {
+		t.Fatalf("CreateOriginal error = %v, want the unsupported profile", err)
+	}
+
+	if fs.Exists(filepath.Join(dirs.Original, card.Filename())) {
+		t.Error("The unconverted original was kept")
+	}
+	if entry := getManifest().Entry(card.UID); entry.SHA256 != "" {
+		t.Errorf("Entry = %+v, want none so the original is tried again", entry)
+	}
+}
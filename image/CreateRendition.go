@@ -0,0 +1,52 @@
+package image
+
+import (
+	"log"
+	"mxdb-tools/csv"
+	"path/filepath"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// CreateRendition resizes the Rendition's source image for a card and
+// saves it in the Rendition's Format and every extra Encoding. It is skipped
+// if the Manifest shows it was built from the same source and settings
+func CreateRendition(card *csv.Card, r *Rendition) error {
+	entry := getManifest().Entry(card.UID)
+	if isRenditionStale(entry, card, r) == false {
+		return nil
+	}
+
+	img, imgErr := imaging.Open(sourcePath(r, card))
+	if imgErr != nil {
+		return imgErr
+	}
+
+	if r.Crop != nil {
+		img = imaging.CropCenter(img, r.Crop.Width, r.Crop.Height)
+	}
+	resizedImg := imaging.Resize(img, r.Width, r.Height, filters[strings.ToLower(r.Filter)])
+
+	// TODO: Final checklist images don't have PreviewActive == true
+	if dirs.Dropbox != "" && r.Dropbox && card.PreviewActive == true {
+		dropboxPath := filepath.Join(dirs.Dropbox, r.Filename(card))
+		if err := save(resizedImg, dropboxPath, r.Format, r.Quality); err != nil {
+			log.Println("Failed to write card to", dropboxPath)
+		}
+	}
+
+	if err := save(resizedImg, r.Path(card), r.Format, r.Quality); err != nil {
+		return err
+	}
+
+	for _, encoding := range r.Encodings {
+		if err := save(resizedImg, r.EncodingPath(card, encoding), encoding.Format, encoding.Quality); err != nil {
+			return err
+		}
+	}
+
+	getManifest().SetRendition(card.UID, r.Name, renditionKey(entry, r))
+
+	return nil
+}
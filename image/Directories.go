@@ -1,32 +1,30 @@
 package image
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+)
 
 type Directories struct {
-	Base      string
-	Original  string
-	Large     string
-	Medium    string
-	Small     string
-	Thumbnail string
-	Dropbox   string
+	Base     string
+	Original string
+	Dropbox  string
+}
+
+// Rendition is the directory a Rendition is saved in
+func (dirs Directories) Rendition(name string) string {
+	return filepath.Join(dirs.Base, name)
 }
 
 func (dirs Directories) Create() error {
 	if err := os.MkdirAll(dirs.Original, 0700); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(dirs.Large, 0700); err != nil {
-		return err
-	}
-	if err := os.MkdirAll(dirs.Medium, 0700); err != nil {
-		return err
-	}
-	if err := os.MkdirAll(dirs.Small, 0700); err != nil {
-		return err
-	}
-	if err := os.MkdirAll(dirs.Thumbnail, 0700); err != nil {
-		return err
+
+	for _, r := range renditions {
+		if err := os.MkdirAll(dirs.Rendition(r.Name), 0700); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,72 @@
+package image
+
+import (
+	"fmt"
+	goimage "image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mxdb-tools/fs"
+	"strings"
+
+	"github.com/gen2brain/webp"
+)
+
+// Encoder writes an image in a single file format
+type Encoder struct {
+	Extension string
+	Encode    func(w io.Writer, img goimage.Image, quality int) error
+}
+
+var encoders = map[string]*Encoder{
+	"jpeg": {
+		Extension: ".jpg",
+		Encode: func(w io.Writer, img goimage.Image, quality int) error {
+			return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+		},
+	},
+	"png": {
+		Extension: ".png",
+		Encode: func(w io.Writer, img goimage.Image, quality int) error {
+			return png.Encode(w, img)
+		},
+	},
+	// libwebp compiled to WebAssembly, so WebP needs neither cgo nor libwebp
+	"webp": {
+		Extension: ".webp",
+		Encode: func(w io.Writer, img goimage.Image, quality int) error {
+			return webp.Encode(w, img, webp.Options{Quality: quality})
+		},
+	},
+}
+
+// RegisterEncoder makes a format, e.g. avif, available to Renditions
+func RegisterEncoder(format string, encoder *Encoder) {
+	encoders[strings.ToLower(format)] = encoder
+}
+
+func encoderFor(format string) (*Encoder, error) {
+	encoder := encoders[strings.ToLower(format)]
+	if encoder == nil {
+		return nil, fmt.Errorf("Unsupported image format: %q", format)
+	}
+
+	return encoder, nil
+}
+
+// save encodes an image to path in the given format
+func save(img goimage.Image, path string, format string, quality int) error {
+	encoder, err := encoderFor(format)
+	if err != nil {
+		return err
+	}
+
+	if quality == 0 {
+		quality = 95
+	}
+
+	// Encode to a temporary file so an interrupted save never leaves a truncated image
+	return fs.WriteAtomic(path, func(w io.Writer) error {
+		return encoder.Encode(w, img, quality)
+	})
+}
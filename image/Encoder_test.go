@@ -0,0 +1,51 @@
+package image
+
+import (
+	goimage "image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	img := goimage.NewNRGBA(goimage.Rect(0, 0, 8, 6))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	img.Set(1, 1, color.NRGBA{R: 0xff, A: 0xff})
+
+	dir := t.TempDir()
+	for _, format := range []string{"jpeg", "png", "webp", "WebP"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(dir, format)
+			if err := save(img, path, format, 80); err != nil {
+				t.Fatal(err)
+			}
+
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			config, _, err := goimage.DecodeConfig(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Width != 8 || config.Height != 6 {
+				t.Errorf("saved a %dx%d image, want 8x6", config.Width, config.Height)
+			}
+		})
+	}
+}
+
+func TestSaveUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "card.avif")
+	if err := save(goimage.NewGray(goimage.Rect(0, 0, 1, 1)), path, "avif", 0); err == nil {
+		t.Fatal("save() succeeded in an unregistered format")
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) == false {
+		t.Error("save() wrote a file in an unregistered format")
+	}
+}
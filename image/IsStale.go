@@ -0,0 +1,36 @@
+package image
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/fs"
+	"path/filepath"
+)
+
+// IsStale returns true if the card's images are missing or were built from
+// another original URL or other Rendition settings. Changes to the file
+// behind the same URL are only found when the images are built
+func IsStale(card *csv.Card) bool {
+	entry := getManifest().Entry(card.UID)
+
+	if entry.OriginalURL != card.OriginalImageURL || entry.SHA256 == "" {
+		return true
+	}
+
+	if fs.Exists(filepath.Join(dirs.Original, card.Filename())) == false {
+		return true
+	}
+
+	for _, r := range renditions {
+		if isRenditionStale(entry, card, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsBuilt returns true if the card's original has been downloaded before. Its
+// images are up to date unless IsStale, or the file behind the URL changed
+func IsBuilt(uid string) bool {
+	return getManifest().Entry(uid).SHA256 != ""
+}
@@ -0,0 +1,80 @@
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+var filters = map[string]imaging.ResampleFilter{
+	"nearest":           imaging.NearestNeighbor,
+	"box":               imaging.Box,
+	"linear":            imaging.Linear,
+	"hermite":           imaging.Hermite,
+	"mitchellnetravali": imaging.MitchellNetravali,
+	"catmullrom":        imaging.CatmullRom,
+	"bspline":           imaging.BSpline,
+	"gaussian":          imaging.Gaussian,
+	"lanczos":           imaging.Lanczos,
+}
+
+// LoadRenditions reads a JSON list of Renditions from a config file
+func LoadRenditions(path string) ([]*Rendition, error) {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []*Rendition
+	if err := json.Unmarshal(body, &loaded); err != nil {
+		return nil, err
+	}
+
+	if err := validateRenditions(loaded); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	return loaded, nil
+}
+
+// validateRenditions makes sure every Rendition can be generated. Sources
+// must be listed before the Renditions that are resized from them
+func validateRenditions(renditions []*Rendition) error {
+	names := map[string]bool{Original: true}
+
+	for _, r := range renditions {
+		if r.Name == "" || r.Name == Original {
+			return fmt.Errorf("Invalid rendition name: %q", r.Name)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("Duplicate rendition: %s", r.Name)
+		}
+		if names[r.Source] == false {
+			return fmt.Errorf("Rendition %s has unknown source: %q", r.Name, r.Source)
+		}
+		if r.Width <= 0 && r.Height <= 0 {
+			return fmt.Errorf("Rendition %s needs a width or height", r.Name)
+		}
+		if _, ok := filters[strings.ToLower(r.Filter)]; ok == false {
+			return fmt.Errorf("Rendition %s has unknown filter: %q", r.Name, r.Filter)
+		}
+		if _, err := encoderFor(r.Format); err != nil {
+			return fmt.Errorf("Rendition %s: %s", r.Name, err)
+		}
+		for _, encoding := range r.Encodings {
+			if _, err := encoderFor(encoding.Format); err != nil {
+				return fmt.Errorf("Rendition %s: %s", r.Name, err)
+			}
+			if strings.EqualFold(encoding.Format, r.Format) {
+				return fmt.Errorf("Rendition %s is already encoded as %s", r.Name, r.Format)
+			}
+		}
+
+		names[r.Name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package image
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalUIDs lists the UIDs of every card with an original image on disk
+func LocalUIDs() ([]string, error) {
+	files, err := ioutil.ReadDir(dirs.Original)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []string
+	for _, file := range files {
+		if file.IsDir() || strings.HasSuffix(file.Name(), ".part") {
+			continue
+		}
+		uids = append(uids, strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())))
+	}
+
+	return uids, nil
+}
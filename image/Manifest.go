@@ -0,0 +1,157 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mxdb-tools/csv"
+	"mxdb-tools/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Manifest records what every card's images were built from, so they are
+// rebuilt exactly when the original or the Rendition settings change
+type Manifest struct {
+	Cards map[string]*ManifestEntry `json:"cards"`
+
+	path   string
+	mu     sync.Mutex
+	saveMu sync.Mutex
+}
+
+// ManifestEntry is the state of a single card's images
+type ManifestEntry struct {
+	OriginalURL  string            `json:"originalUrl"`
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"lastModified,omitempty"`
+	SHA256       string            `json:"sha256"`
+	Renditions   map[string]string `json:"renditions"`
+}
+
+var manifest *Manifest
+var manifestOnce sync.Once
+
+// getManifest loads the manifest from the images directory on first use
+func getManifest() *Manifest {
+	manifestOnce.Do(func() {
+		manifest = &Manifest{
+			Cards: make(map[string]*ManifestEntry),
+			path:  filepath.Join(dirs.Base, "manifest.json"),
+		}
+
+		body, err := ioutil.ReadFile(manifest.path)
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(body, manifest); err != nil || manifest.Cards == nil {
+			manifest.Cards = make(map[string]*ManifestEntry)
+		}
+	})
+
+	return manifest
+}
+
+// Entry returns a copy of the card's entry, which is empty if the card has never been built
+func (m *Manifest) Entry(uid string) ManifestEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := ManifestEntry{Renditions: make(map[string]string)}
+	if existing := m.Cards[uid]; existing != nil {
+		entry = *existing
+		entry.Renditions = make(map[string]string)
+		for name, key := range existing.Renditions {
+			entry.Renditions[name] = key
+		}
+	}
+
+	return entry
+}
+
+// SetOriginal records a downloaded original. Renditions of a different original are forgotten
+func (m *Manifest) SetOriginal(uid string, entry ManifestEntry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := m.Cards[uid]
+	if existing != nil && existing.SHA256 == entry.SHA256 {
+		entry.Renditions = existing.Renditions
+	}
+	if entry.Renditions == nil {
+		entry.Renditions = make(map[string]string)
+	}
+
+	m.Cards[uid] = &entry
+}
+
+// SetRendition records the key a Rendition was built with
+func (m *Manifest) SetRendition(uid string, name string, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry := m.Cards[uid]; entry != nil {
+		entry.Renditions[name] = key
+	}
+}
+
+// Remove forgets a card
+func (m *Manifest) Remove(uid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.Cards, uid)
+}
+
+// Save writes the manifest to the images directory
+func (m *Manifest) Save() error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	body, err := json.MarshalIndent(m, "", "  ")
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return fs.WriteAtomic(m.path, func(w io.Writer) error {
+		_, err := w.Write(body)
+		return err
+	})
+}
+
+// renditionKey hashes the Rendition's settings with the key of its source,
+// so a change anywhere up the chain changes the key
+func renditionKey(entry ManifestEntry, r *Rendition) string {
+	sourceKey := entry.SHA256
+	if r.Source != Original {
+		for _, source := range renditions {
+			if source.Name == r.Source {
+				sourceKey = renditionKey(entry, source)
+			}
+		}
+	}
+
+	settings, _ := json.Marshal(r)
+	hash := sha256.Sum256(append(settings, sourceKey...))
+	return hex.EncodeToString(hash[:])
+}
+
+// isRenditionStale returns true if the Rendition must be rebuilt
+func isRenditionStale(entry ManifestEntry, card *csv.Card, r *Rendition) bool {
+	if entry.Renditions[r.Name] != renditionKey(entry, r) {
+		return true
+	}
+
+	for _, path := range r.Paths(card) {
+		if _, err := os.Stat(path); err != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,178 @@
+package image
+
+import (
+	"io/ioutil"
+	"mxdb-tools/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestManifestSetOriginal(t *testing.T) {
+	m := &Manifest{Cards: make(map[string]*ManifestEntry)}
+
+	m.SetOriginal("A-001", ManifestEntry{OriginalURL: "https://example.com/a.jpg", SHA256: "one"})
+	m.SetRendition("A-001", "small", "key")
+
+	// The same original keeps its Renditions
+	m.SetOriginal("A-001", ManifestEntry{OriginalURL: "https://example.com/a.jpg", ETag: `"v2"`, SHA256: "one"})
+	if entry := m.Entry("A-001"); entry.ETag != `"v2"` || entry.Renditions["small"] != "key" {
+		t.Errorf("Entry() = %+v, want the new ETag and the small Rendition", entry)
+	}
+
+	m.SetOriginal("A-001", ManifestEntry{OriginalURL: "https://example.com/a.jpg", SHA256: "two"})
+	if entry := m.Entry("A-001"); len(entry.Renditions) != 0 {
+		t.Errorf("Entry() = %+v, want the Renditions of the old original forgotten", entry)
+	}
+
+	// A card that was never built has nowhere to record a Rendition
+	m.SetRendition("A-002", "small", "key")
+	if entry := m.Entry("A-002"); entry.SHA256 != "" || len(entry.Renditions) != 0 {
+		t.Errorf("Entry() = %+v for a card that was never built", entry)
+	}
+
+	m.Remove("A-001")
+	if entry := m.Entry("A-001"); entry.SHA256 != "" {
+		t.Errorf("Entry() = %+v after Remove()", entry)
+	}
+}
+
+func TestManifestEntryCopy(t *testing.T) {
+	m := &Manifest{Cards: make(map[string]*ManifestEntry)}
+	m.SetOriginal("A-001", ManifestEntry{SHA256: "one"})
+	m.SetRendition("A-001", "small", "key")
+
+	entry := m.Entry("A-001")
+	entry.SHA256 = "changed"
+	entry.Renditions["small"] = "changed"
+
+	if entry := m.Entry("A-001"); entry.SHA256 != "one" || entry.Renditions["small"] != "key" {
+		t.Errorf("changing the Entry() changed the Manifest: %+v", entry)
+	}
+}
+
+func TestManifestSave(t *testing.T) {
+	useTempDirs(t)
+
+	getManifest().SetOriginal("A-001", ManifestEntry{OriginalURL: "https://example.com/a.jpg", ETag: `"v1"`, SHA256: "one"})
+	getManifest().SetRendition("A-001", "small", "key")
+	if err := getManifest().Save(); err != nil {
+		t.Fatal(err)
+	}
+	saved := getManifest().Entry("A-001")
+
+	// Load it again, the way the next run would
+	manifestOnce = sync.Once{}
+	manifest = nil
+	if entry := getManifest().Entry("A-001"); reflect.DeepEqual(entry, saved) == false {
+		t.Errorf("loaded %+v, want %+v", entry, saved)
+	}
+	if IsBuilt("A-001") == false || IsBuilt("A-002") {
+		t.Error("IsBuilt() doesn't match the loaded manifest")
+	}
+
+	// A corrupt manifest rebuilds everything instead of failing
+	if err := ioutil.WriteFile(filepath.Join(dirs.Base, "manifest.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	manifestOnce = sync.Once{}
+	manifest = nil
+	if IsBuilt("A-001") {
+		t.Error("IsBuilt() read a card from a corrupt manifest")
+	}
+}
+
+func TestRenditionKey(t *testing.T) {
+	large := &Rendition{Name: "large", Source: Original, Height: 100, Format: "jpeg"}
+	small := &Rendition{Name: "small", Source: "large", Height: 10, Format: "jpeg"}
+
+	previous := Renditions()
+	SetRenditions([]*Rendition{large, small})
+	defer SetRenditions(previous)
+
+	entry := ManifestEntry{SHA256: "one"}
+	key := renditionKey(entry, small)
+	if renditionKey(entry, small) != key {
+		t.Fatal("renditionKey() isn't stable")
+	}
+
+	if renditionKey(ManifestEntry{SHA256: "two"}, small) == key {
+		t.Error("renditionKey() didn't change with the original")
+	}
+
+	small.Quality = 80
+	if renditionKey(entry, small) == key {
+		t.Error("renditionKey() didn't change with the Rendition's settings")
+	}
+	small.Quality = 0
+
+	large.Height = 200
+	if renditionKey(entry, small) == key {
+		t.Error("renditionKey() didn't change with its source's settings")
+	}
+}
+
+func TestIsStale(t *testing.T) {
+	useTempDirs(t)
+	useTestRenditions(t)
+
+	card := &csv.Card{UID: "A-001", OriginalImageURL: "https://example.com/a.jpg"}
+	if IsStale(card) == false {
+		t.Fatal("IsStale() = false for a card that was never built")
+	}
+
+	original := filepath.Join(dirs.Original, card.Filename())
+	small := Renditions()[0]
+	for _, path := range []string{original, small.Path(card)} {
+		if err := ioutil.WriteFile(path, []byte("image"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entry := ManifestEntry{OriginalURL: card.OriginalImageURL, SHA256: "one"}
+	getManifest().SetOriginal(card.UID, entry)
+	getManifest().SetRendition(card.UID, small.Name, renditionKey(entry, small))
+	if IsStale(card) {
+		t.Fatal("IsStale() = true for a card that was just built")
+	}
+
+	tests := []struct {
+		name   string
+		change func()
+		undo   func()
+	}{
+		{
+			"new original url",
+			func() { card.OriginalImageURL = "https://example.com/b.jpg" },
+			func() { card.OriginalImageURL = "https://example.com/a.jpg" },
+		},
+		{
+			"new rendition settings",
+			func() { small.Quality = 50 },
+			func() { small.Quality = 90 },
+		},
+		{
+			"missing rendition",
+			func() { os.Rename(small.Path(card), small.Path(card)+".moved") },
+			func() { os.Rename(small.Path(card)+".moved", small.Path(card)) },
+		},
+		{
+			"missing original",
+			func() { os.Rename(original, original+".moved") },
+			func() { os.Rename(original+".moved", original) },
+		},
+	}
+
+	for _, test := range tests {
+		test.change()
+		if IsStale(card) == false {
+			t.Errorf("IsStale() = false with a %s", test.name)
+		}
+		test.undo()
+		if IsStale(card) {
+			t.Fatalf("IsStale() = true after undoing the %s", test.name)
+		}
+	}
+}
@@ -7,29 +7,25 @@ import (
 )
 
 func RemoveAll(card *csv.Card) error {
-	ogPath := filepath.Join(dirs.Original, card.Filename())
-	largePath := filepath.Join(dirs.Large, card.Filename())
-	mediumPath := filepath.Join(dirs.Medium, card.Filename())
-	smallPath := filepath.Join(dirs.Small, card.Filename())
-	thumbnailPath := filepath.Join(dirs.Thumbnail, card.Filename())
-
-	if err := os.Remove(ogPath); err != nil {
-		return err
-	}
-
-	if err := os.Remove(largePath); err != nil {
+	if err := remove(filepath.Join(dirs.Original, card.Filename())); err != nil {
 		return err
 	}
 
-	if err := os.Remove(mediumPath); err != nil {
-		return err
+	for _, r := range renditions {
+		for _, path := range r.Paths(card) {
+			if err := remove(path); err != nil {
+				return err
+			}
+		}
 	}
 
-	if err := os.Remove(smallPath); err != nil {
-		return err
-	}
+	getManifest().Remove(card.UID)
+	return getManifest().Save()
+}
 
-	if err := os.Remove(thumbnailPath); err != nil {
+// remove deletes a file, ignoring files that were never created
+func remove(path string) error {
+	if err := os.Remove(path); err != nil && os.IsNotExist(err) == false {
 		return err
 	}
 
@@ -0,0 +1,119 @@
+package image
+
+import (
+	"mxdb-tools/csv"
+	"path/filepath"
+)
+
+// Original is the name Renditions use as their Source to resize the downloaded image
+const Original = "original"
+
+// Rendition describes an image size that is generated for every card
+type Rendition struct {
+	Name      string     `json:"name"`
+	Source    string     `json:"source"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Crop      *Crop      `json:"crop,omitempty"`
+	Filter    string     `json:"filter"`
+	Format    string     `json:"format"`
+	Quality   int        `json:"quality"`
+	Encodings []Encoding `json:"encodings,omitempty"`
+	Dropbox   bool       `json:"dropbox,omitempty"`
+}
+
+// Crop is the size an image is cropped to, around its center, before resizing
+type Crop struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Encoding is an extra format a Rendition is saved in, alongside its Format
+type Encoding struct {
+	Format  string `json:"format"`
+	Quality int    `json:"quality"`
+}
+
+// Filename turns the card's UID into a filename with the Rendition's extension
+func (r *Rendition) Filename(card *csv.Card) string {
+	return filename(card, r.Format)
+}
+
+// Path is where the Rendition of a card is saved
+func (r *Rendition) Path(card *csv.Card) string {
+	return filepath.Join(dirs.Rendition(r.Name), r.Filename(card))
+}
+
+// EncodingFilename turns the card's UID into a filename with the Encoding's extension
+func (r *Rendition) EncodingFilename(card *csv.Card, encoding Encoding) string {
+	return filename(card, encoding.Format)
+}
+
+// EncodingPath is where an extra Encoding of the Rendition is saved
+func (r *Rendition) EncodingPath(card *csv.Card, encoding Encoding) string {
+	return filepath.Join(dirs.Rendition(r.Name), r.EncodingFilename(card, encoding))
+}
+
+// Paths lists the Rendition's Path and every EncodingPath
+func (r *Rendition) Paths(card *csv.Card) []string {
+	paths := []string{r.Path(card)}
+	for _, encoding := range r.Encodings {
+		paths = append(paths, r.EncodingPath(card, encoding))
+	}
+
+	return paths
+}
+
+// DefaultRenditions are the sizes used when no config is loaded
+func DefaultRenditions() []*Rendition {
+	return []*Rendition{
+		{
+			Name:    "large",
+			Source:  Original,
+			Height:  1000,
+			Crop:    &Crop{Width: 680 + (30 * 2), Height: 980 + (30 * 2)},
+			Filter:  "box",
+			Format:  "jpeg",
+			Quality: 95,
+			Dropbox: true,
+		},
+		{Name: "medium", Source: "large", Height: 400, Filter: "box", Format: "jpeg", Quality: 95},
+		{Name: "small", Source: "large", Height: 200, Filter: "box", Format: "jpeg", Quality: 95},
+		{Name: "thumbnail", Source: "large", Height: 100, Filter: "box", Format: "jpeg", Quality: 95},
+	}
+}
+
+// Renditions returns the Renditions currently in use
+func Renditions() []*Rendition {
+	return renditions
+}
+
+// SetRenditions replaces the Renditions that are generated for every card
+func SetRenditions(r []*Rendition) {
+	renditions = r
+}
+
+// filename is the card's UID with the extension of the format
+func filename(card *csv.Card, format string) string {
+	encoder, err := encoderFor(format)
+	if err != nil {
+		return card.UID
+	}
+
+	return card.UID + encoder.Extension
+}
+
+// sourcePath is where the image a Rendition is resized from is saved
+func sourcePath(r *Rendition, card *csv.Card) string {
+	if r.Source == Original {
+		return filepath.Join(dirs.Original, card.Filename())
+	}
+
+	for _, source := range renditions {
+		if source.Name == r.Source {
+			return source.Path(card)
+		}
+	}
+
+	return ""
+}
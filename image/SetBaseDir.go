@@ -0,0 +1,11 @@
+package image
+
+import "path/filepath"
+
+// SetBaseDir moves the directory the images are saved in
+func SetBaseDir(baseDir string) {
+	if dirs != nil {
+		dirs.Base = baseDir
+		dirs.Original = filepath.Join(dirs.Base, "original/")
+	}
+}
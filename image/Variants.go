@@ -0,0 +1,35 @@
+package image
+
+import (
+	"mxdb-tools/csv"
+	"path"
+	"strings"
+)
+
+// Variants maps "<rendition>.<format>" to the URL of each extra Encoding. The
+// URLs are the rendition's URL in the csv with the Encoding's extension
+func Variants(card *csv.Card) map[string]string {
+	variants := make(map[string]string)
+
+	for _, r := range renditions {
+		url := card.ImageURL(r.Name)
+		if url == "" {
+			continue
+		}
+
+		for _, encoding := range r.Encodings {
+			encoder, err := encoderFor(encoding.Format)
+			if err != nil {
+				continue
+			}
+			key := r.Name + "." + strings.ToLower(encoding.Format)
+			variants[key] = strings.TrimSuffix(url, path.Ext(url)) + encoder.Extension
+		}
+	}
+
+	if len(variants) == 0 {
+		return nil
+	}
+
+	return variants
+}
@@ -0,0 +1,254 @@
+package image
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	goimage "image"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const downloadAttempts = 5
+
+var downloadClient = &http.Client{Timeout: 5 * time.Minute}
+
+// downloaded is the outcome of a download
+type downloaded struct {
+	NotModified  bool
+	ETag         string
+	LastModified string
+	SHA256       string
+}
+
+// retryableError is a failure that may succeed if the download is tried again
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+// download fetches url into path. The body is written to path.part, which is
+// resumed with a Range request when a retry follows an interrupted transfer,
+// and only renamed to path once it is verified to be a complete image.
+// With a validator from a previous download the request is conditional
+func download(url string, path string, validator ManifestEntry) (*downloaded, error) {
+	partPath := path + ".part"
+	os.Remove(partPath)
+
+	// resume holds the validators of the response the part file came from
+	var resume downloaded
+	var result *downloaded
+	var err error
+	for attempt := 0; attempt < downloadAttempts; attempt++ {
+		if attempt > 0 {
+			wait := time.Duration(1<<uint(attempt-1)) * time.Second
+			log.Printf("Retrying %s in %s: %s", url, wait, err)
+			time.Sleep(wait)
+		}
+
+		result, err = fetch(url, partPath, validator, resume)
+		if result != nil {
+			resume = *result
+		}
+		if err == nil {
+			break
+		}
+		if _, ok := err.(retryableError); ok == false {
+			break
+		}
+	}
+
+	if err != nil {
+		os.Remove(partPath)
+		return nil, err
+	}
+
+	if result.NotModified {
+		return result, nil
+	}
+
+	if err := verifyImage(partPath); err != nil {
+		os.Remove(partPath)
+		return nil, fmt.Errorf("Invalid image at %s: %s", url, err)
+	}
+
+	sum, err := sha256File(partPath)
+	if err != nil {
+		return nil, err
+	}
+	result.SHA256 = sum
+
+	if err := os.Rename(partPath, path); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// fetch makes a single request. The part file is resumed if it came from a
+// response with a strong validator, otherwise the download starts over. The
+// returned validators are set whenever the server sent the file, even if
+// the transfer failed
+func fetch(url string, partPath string, validator ManifestEntry, resume downloaded) (*downloaded, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	ifRange := resume.LastModified
+	if resume.ETag != "" && strings.HasPrefix(resume.ETag, "W/") == false {
+		ifRange = resume.ETag
+	}
+
+	if offset > 0 && ifRange != "" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", ifRange)
+	} else {
+		offset = 0
+		if validator.ETag != "" {
+			req.Header.Set("If-None-Match", validator.ETag)
+		}
+		if validator.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validator.LastModified)
+		}
+	}
+
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return nil, retryableError{err}
+	}
+
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		os.Remove(partPath)
+		return &downloaded{NotModified: true}, nil
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		// A server that ignores the offset would corrupt the part file, so it starts over
+		contentRange := resp.Header.Get("Content-Range")
+		if start, ok := contentRangeStart(contentRange); ok == false || start != offset {
+			os.Remove(partPath)
+			return nil, retryableError{fmt.Errorf("Unable to resume download at byte %d: Content-Range %q", offset, contentRange)}
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		os.Remove(partPath)
+		return nil, retryableError{fmt.Errorf("Unable to resume download: %s", resp.Status)}
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return nil, retryableError{fmt.Errorf("Unable to download: %s", resp.Status)}
+	default:
+		return nil, fmt.Errorf("Unable to download %s: %s", url, resp.Status)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if isImageContentType(contentType) == false {
+		return nil, fmt.Errorf("Unable to download %s: unexpected Content-Type %q", url, contentType)
+	}
+
+	result := &downloaded{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	// A 206 continues the response the part file came from
+	if resp.StatusCode == http.StatusPartialContent {
+		result = &resume
+	}
+
+	partFile, err := os.OpenFile(partPath, flags, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	written, copyErr := io.Copy(partFile, resp.Body)
+	if err := partFile.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return result, retryableError{copyErr}
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return result, retryableError{fmt.Errorf("Incomplete download: %d of %d bytes", written, resp.ContentLength)}
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		log.Println("Downloaded:", url)
+	} else {
+		log.Println("Resumed:", url)
+	}
+
+	return result, nil
+}
+
+// contentRangeStart returns the first byte of a "bytes <start>-<end>/<size>" Content-Range
+func contentRangeStart(contentRange string) (int64, bool) {
+	if strings.HasPrefix(contentRange, "bytes ") == false {
+		return 0, false
+	}
+
+	dash := strings.Index(contentRange, "-")
+	if dash == -1 {
+		return 0, false
+	}
+
+	start, err := strconv.ParseInt(strings.TrimSpace(contentRange[len("bytes "):dash]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return start, true
+}
+
+// isImageContentType allows image types and the generic types some hosts serve images as
+func isImageContentType(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+
+	return (strings.HasPrefix(contentType, "image/") ||
+		strings.HasPrefix(contentType, "application/octet-stream") ||
+		strings.HasPrefix(contentType, "binary/octet-stream"))
+}
+
+// verifyImage fully decodes the file, which fails for truncated or non-image data
+func verifyImage(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = goimage.Decode(bytes.NewReader(data))
+	return err
+}
+
+func sha256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,176 @@
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestDownload(t *testing.T) {
+	body := testJPEG(t, nil)
+	server := serveImage(body)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "A-001.jpg")
+	result, err := download(server.URL, path, ManifestEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256(body)
+	if result.NotModified || result.ETag != `"v1"` || result.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("download() = %+v", result)
+	}
+	if saved, err := ioutil.ReadFile(path); err != nil || string(saved) != string(body) {
+		t.Errorf("download() saved %d bytes, %v", len(saved), err)
+	}
+	if _, err := os.Stat(path + ".part"); os.IsNotExist(err) == false {
+		t.Error("download() left the part file behind")
+	}
+}
+
+func TestDownloadNotModified(t *testing.T) {
+	server := newVersionedServer(testJPEG(t, nil), `"v1"`)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "A-001.jpg")
+	result, err := download(server.URL, path, ManifestEntry{ETag: `"v1"`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NotModified == false {
+		t.Errorf("download() = %+v, want not modified", result)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) == false {
+		t.Error("download() wrote a file that wasn't modified")
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	body := testJPEG(t, nil)
+	half := len(body) / 2
+
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		first := len(ranges) == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Header().Set("ETag", `"v1"`)
+
+		if first {
+			// Promise the whole image, then drop the connection halfway through it
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Write(body[:half])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", half, len(body)-1, len(body)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(body[half:])
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "A-001.jpg")
+	if _, err := download(server.URL, path, ManifestEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{" ", fmt.Sprintf(`bytes=%d- "v1"`, half)}
+	if fmt.Sprint(ranges) != fmt.Sprint(want) {
+		t.Errorf("download() requested %q, want %q", ranges, want)
+	}
+	if saved, err := ioutil.ReadFile(path); err != nil || string(saved) != string(body) {
+		t.Errorf("download() resumed into %d bytes, want %d: %v", len(saved), len(body), err)
+	}
+}
+
+func TestDownloadFailures(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"not found", http.StatusNotFound, "text/html", "Not found"},
+		{"forbidden", http.StatusForbidden, "text/html", "Forbidden"},
+		{"html", http.StatusOK, "text/html", "<html>"},
+		{"not an image", http.StatusOK, "image/jpeg", "not a jpeg"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", test.contentType)
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			path := filepath.Join(t.TempDir(), "A-001.jpg")
+			if _, err := download(server.URL, path, ManifestEntry{}); err == nil {
+				t.Fatal("download() succeeded")
+			}
+			if requests != 1 {
+				t.Errorf("download() made %d requests, want 1", requests)
+			}
+			for _, leftover := range []string{path, path + ".part"} {
+				if _, err := os.Stat(leftover); os.IsNotExist(err) == false {
+					t.Errorf("download() left %s behind", filepath.Base(leftover))
+				}
+			}
+		})
+	}
+}
+
+func TestContentRangeStart(t *testing.T) {
+	tests := []struct {
+		contentRange string
+		want         int64
+		wantOK       bool
+	}{
+		{"bytes 100-199/200", 100, true},
+		{"bytes 0-99/*", 0, true},
+		{"bytes */200", 0, false},
+		{"items 100-199/200", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		start, ok := contentRangeStart(test.contentRange)
+		if start != test.want || ok != test.wantOK {
+			t.Errorf("contentRangeStart(%q) = %d, %t, want %d, %t", test.contentRange, start, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestIsImageContentType(t *testing.T) {
+	tests := map[string]bool{
+		"image/jpeg":               true,
+		"IMAGE/PNG":                true,
+		"application/octet-stream": true,
+		"binary/octet-stream":      true,
+		"text/html; charset=utf-8": false,
+		"":                         false,
+	}
+
+	for contentType, want := range tests {
+		if got := isImageContentType(contentType); got != want {
+			t.Errorf("isImageContentType(%q) = %t, want %t", contentType, got, want)
+		}
+	}
+}
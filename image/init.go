@@ -7,6 +7,7 @@ import (
 )
 
 var dirs *Directories
+var renditions = DefaultRenditions()
 
 func init() {
 	cwd, cwdErr := os.Getwd()
@@ -17,12 +18,4 @@ func init() {
 	dirs = &Directories{}
 	dirs.Base = filepath.Join(cwd, "images/")
 	dirs.Original = filepath.Join(dirs.Base, "original/")
-	dirs.Large = filepath.Join(dirs.Base, "large/")
-	dirs.Medium = filepath.Join(dirs.Base, "medium/")
-	dirs.Small = filepath.Join(dirs.Base, "small/")
-	dirs.Thumbnail = filepath.Join(dirs.Base, "thumbnail/")
-
-	if err := dirs.Create(); err != nil {
-		log.Fatal(err)
-	}
 }
@@ -0,0 +1,46 @@
+package image
+
+import (
+	"bytes"
+	goimage "image"
+	"io/ioutil"
+	"log"
+	"mxdb-tools/icc"
+)
+
+// normalizeColor converts an image with an embedded color profile other
+// than sRGB to sRGB, replacing the file
+func normalizeColor(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	profileData, err := icc.Extract(data)
+	if err != nil || profileData == nil {
+		return err
+	}
+
+	profile, err := icc.Parse(profileData)
+	if err != nil {
+		return err
+	}
+
+	if profile.IsSRGB() {
+		return nil
+	}
+
+	img, format, err := goimage.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	converted, err := profile.ToSRGB(img)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Converting %s (%s) to sRGB: %s", profile.Description, profile.ColorSpace, path)
+
+	return save(converted, path, format, 95)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"mxdb-tools/csv"
+	"mxdb-tools/image"
+	"os"
+)
+
+func runImagesBuild(args []string) int {
+	ok := parseFlags("images build", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&dropboxDir, "dropbox", "", "Dropbox directory where large images are copied")
+	})
+	if ok == false {
+		return exitUsage
+	}
+
+	image.SetDropboxDir(dropboxDir)
+
+	cards, err := fetchCards()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	if err := image.CreateDirectories(); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	summary := image.Build(cards, concurrency)
+	summary.Print(os.Stderr)
+
+	if len(summary.Errors) != 0 {
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func runImagesClean(args []string) int {
+	if parseFlags("images clean", args, nil) == false {
+		return exitUsage
+	}
+
+	cards, err := fetchCards()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	inCSV := make(map[string]bool)
+	for _, card := range cards {
+		inCSV[card.UID] = true
+	}
+
+	uids, err := image.LocalUIDs()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	code := exitOK
+	for _, uid := range uids {
+		if inCSV[uid] {
+			continue
+		}
+
+		if err := image.RemoveAll(&csv.Card{UID: uid}); err != nil {
+			log.Println(err)
+			code = exitFailure
+			continue
+		}
+		log.Println("Removed images:", uid)
+	}
+
+	return code
+}
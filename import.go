@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"mxdb-tools/merge"
+	"os"
+	"strings"
+)
+
+var importOut string
+var baselineSnapshot string
+
+// runImport writes the cards in the API as csv rows in the order of the sheet, so
+// fixes made in the API can be copied back. Fields only changed in the sheet since
+// the last sync are kept, and fields changed in both are reported as conflicts
+// and keep the sheet's value
+func runImport(args []string) int {
+	ok := parseFlags("import", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&importOut, "out", "-", "File to write the csv to, or - for stdout")
+		flags.StringVar(&baselinePath, "baseline", "baseline.csv", "File the cards were recorded in by the last sync")
+		flags.StringVar(&baselineSnapshot, "baseline-snapshot", "", "Use a snapshot taken at the last sync as the baseline instead")
+	})
+	if ok == false {
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		log.Println(err)
+		return exitUsage
+	}
+
+	cards, err := fetchCards()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	gqlCards, err := client.FetchCards()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	baseline, err := importBaseline()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	if len(baseline) != 0 {
+		conflicts := merge.Conflicts(cards, gqlCards, baseline)
+		merge.PrintConflicts(os.Stderr, conflicts)
+		fmt.Fprintf(os.Stderr, "%d conflicts\n", len(conflicts))
+	}
+
+	rows, err := importRows(cards, gqlCards, baseline)
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	err = writeOutput(importOut, func(w io.Writer) error {
+		return csv.Write(w, rows)
+	})
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// importBaseline reads the cards as they were after the last sync, from the
+// --baseline-snapshot if there is one
+func importBaseline() (map[string]*csv.Card, error) {
+	if baselineSnapshot != "" {
+		snapshot, err := gql.ReadSnapshot(baselineSnapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		baseline := make(map[string]*csv.Card)
+		for _, gqlCard := range snapshot.Cards {
+			card, err := gqlCard.CSV()
+			if err != nil {
+				return nil, err
+			}
+			baseline[gqlCard.UID] = card
+		}
+		return baseline, nil
+	}
+
+	if baselinePath == "" {
+		return nil, nil
+	}
+
+	baseline, err := merge.ReadBaseline(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return baseline.Cards, nil
+}
+
+// importRows merges the card from the API into each row of the sheet. Rows that
+// aren't in the API yet are kept, and cards only in the API are added at the end.
+// Rows without a baseline are replaced by the API's card. It is an error for a
+// card in the API not to fit in a row
+func importRows(cards []*csv.Card, gqlCards []*gql.Card, baseline map[string]*csv.Card) ([]*csv.Card, error) {
+	apiCards := make(map[string]*gql.Card)
+	for _, gqlCard := range gqlCards {
+		apiCards[gqlCard.UID] = gqlCard
+	}
+
+	var rows []*csv.Card
+	seen := make(map[string]bool)
+	for _, card := range cards {
+		seen[card.UID] = true
+		gqlCard := apiCards[card.UID]
+		if gqlCard == nil {
+			rows = append(rows, card)
+			continue
+		}
+
+		row, err := gqlCard.CSV()
+		if err != nil {
+			return nil, err
+		}
+
+		if base := baseline[card.UID]; base != nil {
+			// The API is merged into the sheet, so changes only made in the sheet
+			// are kept and conflicts keep the sheet's value
+			row = merge.Card(row, card, base).Card
+		} else if changed := merge.ChangedFields(card, row); len(changed) != 0 {
+			log.Printf("%s isn't in the baseline, replacing the sheet's %s with the API's", card.UID, strings.Join(changed, ", "))
+		}
+		rows = append(rows, row)
+	}
+
+	for _, gqlCard := range gqlCards {
+		if seen[gqlCard.UID] == false {
+			row, err := gqlCard.CSV()
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
@@ -1,116 +1,200 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"mxdb-tools/csv"
+	"mxdb-tools/fs"
 	"mxdb-tools/gql"
 	"mxdb-tools/image"
+	"os"
+	"runtime"
+	"strings"
+	"time"
 )
 
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// Global flags, accepted before or after the command
 var token string
-var dropboxDir string
+var endpoint string
+var source string
+var imagesDir string
+var lookupsPath string
+var renditionsPath string
+var concurrency int
+var timeout time.Duration
+var retries int
+var rateLimit float64
+
+// command is a single step of the pipeline that can be run on its own
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) int
+}
+
+var commands = []*command{
+	{"sync", "Build images and update the API to match the csv", runSync},
+	{"diff", "Print the changes sync would make, or the changes between two snapshots", runDiff},
+	{"validate", "Check every row of the csv", runValidate},
+	{"images build", "Download and resize the images of every card", runImagesBuild},
+	{"images clean", "Remove images of cards that are no longer in the csv", runImagesClean},
+	{"fetch", "Print the cards in the API as JSON", runFetch},
+	{"snapshot", "Save the cards, traits and stat ranks in the API to a file", runSnapshot},
+	{"import", "Print the cards in the API as csv rows in the order of the sheet", runImport},
+	{"export", "Write the cards as JSON, csv and SQLite", runExport},
+}
 
-func init() {
-	flag.StringVar(&token, "token", "", "Pass the token for the graphql API")
-	flag.StringVar(&dropboxDir, "dropbox", "", "Dropbox directory where large images are copied")
+func globalFlags(flags *flag.FlagSet) {
+	flags.StringVar(&token, "token", "", "Pass the token for the graphql API")
+	flags.StringVar(&endpoint, "endpoint", gql.DefaultEndpoint, "URL of the graphql API")
+	flags.StringVar(&source, "source", "sheet", "Where to read the card csv from: sheet, a file path, a URL or - for stdin")
+	flags.StringVar(&imagesDir, "images", "images", "Directory images are saved in")
+	flags.StringVar(&lookupsPath, "lookups", "", "File to cache stat rank and trait IDs in")
+	flags.StringVar(&renditionsPath, "renditions", "", "JSON file listing the image renditions to generate")
+	flags.IntVar(&concurrency, "concurrency", runtime.NumCPU(), "Number of cards to work on at once")
+	flags.DurationVar(&timeout, "timeout", gql.DefaultTimeout, "Time limit for each request to the graphql API")
+	flags.IntVar(&retries, "retries", gql.DefaultRetries, "Number of times a failed graphql query is retried. Mutations are only retried if they never reached the API")
+	flags.Float64Var(&rateLimit, "rate-limit", 0, "Maximum requests per second to the graphql API, or 0 for no limit")
 }
 
 func main() {
+	flag.Usage = usage
+	globalFlags(flag.CommandLine)
 	flag.Parse()
 
-	if token == "" {
-		log.Println("Token required. Use --token")
-		return
-	} else {
-		gql.SetToken(token)
+	args := flag.Args()
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			os.Exit(cmd.run(args[len(words):]))
+		}
 	}
 
-	image.SetDropboxDir(dropboxDir)
+	usage()
+	os.Exit(exitUsage)
+}
 
-	cards, err := csv.Fetch()
-	if err != nil {
-		log.Println(err)
-		return
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [global flags] <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.usage)
 	}
+	fmt.Fprintln(os.Stderr, "\nGlobal flags:")
+	flag.PrintDefaults()
+}
 
-	for _, card := range cards {
-		if err := image.CreateAll(card); err != nil {
-			log.Println(err)
-			continue
-		}
+// parseFlags parses a command's flags along with the global flags
+func parseFlags(name string, args []string, define func(*flag.FlagSet)) bool {
+	// Keep any global flags that were passed before the command
+	preset := make(map[string]string)
+	flag.CommandLine.Visit(func(f *flag.Flag) {
+		preset[f.Name] = f.Value.String()
+	})
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	globalFlags(flags)
+	if define != nil {
+		define(flags)
 	}
 
-	gqlCards, err := gql.FetchCards()
-	if err != nil {
-		log.Println(err)
-		return
+	for name, value := range preset {
+		flags.Set(name, value)
 	}
-	// TODO: Should this be the output of loadGraphQL?
-	currentCards := make(map[string]*gql.Card)
-	for _, gqlCard := range gqlCards {
-		currentCards[gqlCard.UID] = gqlCard
+
+	if err := flags.Parse(args); err != nil {
+		return false
 	}
 
-	var createCards []*csv.Card
-	for _, card := range cards {
-		currentCard := currentCards[card.UID]
+	if err := setup(); err != nil {
+		log.Println(err)
+		return false
+	}
 
-		if currentCard == nil {
-			createCards = append(createCards, card)
-			continue
-		}
+	return true
+}
 
-		if currentCard.Preview.IsEqual(card) == false {
-			resp, err := currentCard.Preview.Update(card)
-			if err != nil {
-				log.Println(err)
-			} else {
-				log.Printf("Preview updated: %s", resp)
-			}
-		}
+// setup applies the global flags to the packages that use them
+func setup() error {
+	image.SetBaseDir(imagesDir)
 
-		if currentCard.Image.IsEqual(card) == false {
-			var resp []byte
-			var err error
-			if currentCard.Image.IsEmpty() {
-				resp, err = currentCard.CreateImage(card)
-			} else {
-				if card.OriginalImageURL != currentCard.Image.Original {
-					if err := image.RemoveAll(card); err != nil {
-						log.Println(err)
-						continue
-					}
-					if err := image.CreateAll(card); err != nil {
-						log.Println(err)
-						continue
-					}
-				}
-				resp, err = currentCard.Image.Update(card)
-			}
-			if err != nil {
-				log.Println(err)
-			} else {
-				log.Printf("Image updated: %s", resp)
-			}
+	if renditionsPath != "" {
+		renditions, err := image.LoadRenditions(renditionsPath)
+		if err != nil {
+			return err
 		}
+		image.SetRenditions(renditions)
+	}
 
-		if currentCard.IsEqual(card) == false {
-			resp, err := currentCard.Update(card)
-			if err != nil {
-				log.Println(err)
-			} else {
-				log.Printf("Card updated: %s", resp)
-			}
-		}
+	return nil
+}
+
+// newClient creates a Client for the API from the global flags
+func newClient() (*gql.Client, error) {
+	if token == "" {
+		return nil, errors.New("Token required. Use --token")
 	}
 
-	for _, card := range createCards {
-		respBody, err := gql.CreateCard(card)
+	return gql.NewClient(token,
+		gql.WithEndpoint(endpoint),
+		gql.WithTimeout(timeout),
+		gql.WithRetries(retries, gql.DefaultBackoff),
+		gql.WithRateLimit(rateLimit),
+	), nil
+}
+
+// fetchCards reads the csv from the --source
+func fetchCards() ([]*csv.Card, error) {
+	return csv.Fetch(csv.ParseSource(source))
+}
+
+// seedLookups seeds the client from the --lookups file, or fetches the lookups
+// and caches them in it
+func seedLookups(client *gql.Client) error {
+	if lookupsPath != "" && fs.Exists(lookupsPath) {
+		lookups, err := gql.ReadLookups(lookupsPath)
 		if err != nil {
-			log.Println(err)
-			continue
+			return err
 		}
-		log.Printf("%s", respBody)
+		client.SetLookups(lookups)
+		return nil
+	}
+
+	lookups, err := client.LoadLookups()
+	if err != nil {
+		return err
 	}
+
+	if lookupsPath != "" {
+		return lookups.Write(lookupsPath)
+	}
+
+	return nil
+}
+
+// writeOutput writes to the file at path, or to stdout if path is -
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
 }
@@ -0,0 +1,53 @@
+package merge
+
+import (
+	"io"
+	"mxdb-tools/csv"
+	"mxdb-tools/fs"
+	"sort"
+)
+
+// Baseline is every card as it was after the last successful sync. It is kept
+// as csv, so it can be read and edited like the sheet
+type Baseline struct {
+	Cards map[string]*csv.Card
+
+	path string
+}
+
+// ReadBaseline loads the Baseline saved at path. It is empty if nothing has been synced yet
+func ReadBaseline(path string) (*Baseline, error) {
+	baseline := &Baseline{Cards: make(map[string]*csv.Card), path: path}
+	if fs.Exists(path) == false {
+		return baseline, nil
+	}
+
+	cards, err := csv.Fetch(csv.FileSource{Path: path})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range cards {
+		baseline.Cards[card.UID] = card
+	}
+
+	return baseline, nil
+}
+
+// Save writes the Baseline back to where it was read from
+func (baseline *Baseline) Save() error {
+	var uids []string
+	for uid := range baseline.Cards {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	var cards []*csv.Card
+	for _, uid := range uids {
+		cards = append(cards, baseline.Cards[uid])
+	}
+
+	return fs.WriteAtomic(baseline.path, func(w io.Writer) error {
+		return csv.Write(w, cards)
+	})
+}
@@ -0,0 +1,68 @@
+package merge
+
+import (
+	"mxdb-tools/csv"
+	"reflect"
+)
+
+// Result is the three-way merge of a card in the sheet and the API with the baseline
+type Result struct {
+	// Card is the sheet's card with the fields that were only changed in the
+	// API, or changed in both, set to the API's values so syncing it leaves them alone
+	Card *csv.Card
+	// Baseline is what both sides agree on once Card is synced. Conflicting
+	// fields keep their old baseline value, so they stay conflicts until resolved
+	Baseline *csv.Card
+	// Kept lists the fields that were only changed in the API
+	Kept      []string
+	Conflicts []*Conflict
+}
+
+// Card merges the sheet's card with the API's, using the baseline from the last sync
+func Card(sheet *csv.Card, api *csv.Card, base *csv.Card) *Result {
+	merged := *sheet
+	baseline := *sheet
+	result := &Result{Card: &merged, Baseline: &baseline}
+
+	for _, f := range fields {
+		sheetValue, apiValue, baseValue := f.get(sheet), f.get(api), f.get(base)
+
+		switch {
+		case reflect.DeepEqual(sheetValue, apiValue):
+		case reflect.DeepEqual(apiValue, baseValue):
+			// Only changed in the sheet, so it is synced
+		case reflect.DeepEqual(sheetValue, baseValue):
+			f.copy(result.Card, api)
+			result.Kept = append(result.Kept, f.path)
+		default:
+			f.copy(result.Card, api)
+			f.copy(result.Baseline, base)
+			result.Conflicts = append(result.Conflicts, &Conflict{
+				UID:      sheet.UID,
+				Field:    f.path,
+				Baseline: baseValue,
+				Sheet:    sheetValue,
+				API:      apiValue,
+			})
+		}
+	}
+
+	return result
+}
+
+// IsChanged returns true if any field of the card differs from the baseline
+func IsChanged(card *csv.Card, base *csv.Card) bool {
+	return len(ChangedFields(card, base)) != 0
+}
+
+// ChangedFields lists the fields of the card that differ from the baseline
+func ChangedFields(card *csv.Card, base *csv.Card) []string {
+	var changed []string
+	for _, f := range fields {
+		if reflect.DeepEqual(f.get(card), f.get(base)) == false {
+			changed = append(changed, f.path)
+		}
+	}
+
+	return changed
+}
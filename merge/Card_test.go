@@ -0,0 +1,132 @@
+package merge
+
+import (
+	"mxdb-tools/csv"
+	"reflect"
+	"testing"
+)
+
+func testCard(edit func(c *csv.Card)) *csv.Card {
+	card := &csv.Card{
+		UID:      "1-001",
+		Rarity:   "C",
+		Number:   1,
+		Set:      "1",
+		Title:    "Card",
+		Type:     "Battle",
+		Traits:   csv.List{"Hero"},
+		MP:       2,
+		Symbol:   "const",
+		Effect:   "Draw a card.",
+		Strength: csv.NewStat(3),
+	}
+	if edit != nil {
+		edit(card)
+	}
+
+	return card
+}
+
+func TestCard(t *testing.T) {
+	tests := []struct {
+		name          string
+		sheet         *csv.Card
+		api           *csv.Card
+		wantCard      *csv.Card
+		wantBaseline  *csv.Card
+		wantKept      []string
+		wantConflicts []string
+	}{
+		{
+			name:         "unchanged",
+			sheet:        testCard(nil),
+			api:          testCard(nil),
+			wantCard:     testCard(nil),
+			wantBaseline: testCard(nil),
+		},
+		{
+			name:         "changed in the sheet",
+			sheet:        testCard(func(c *csv.Card) { c.Title = "Sheet" }),
+			api:          testCard(nil),
+			wantCard:     testCard(func(c *csv.Card) { c.Title = "Sheet" }),
+			wantBaseline: testCard(func(c *csv.Card) { c.Title = "Sheet" }),
+		},
+		{
+			name:         "changed in the API",
+			sheet:        testCard(nil),
+			api:          testCard(func(c *csv.Card) { c.MP = 4 }),
+			wantCard:     testCard(func(c *csv.Card) { c.MP = 4 }),
+			wantBaseline: testCard(nil),
+			wantKept:     []string{"mp"},
+		},
+		{
+			name:         "changed the same in both",
+			sheet:        testCard(func(c *csv.Card) { c.Strength = csv.Stat{} }),
+			api:          testCard(func(c *csv.Card) { c.Strength = csv.Stat{} }),
+			wantCard:     testCard(func(c *csv.Card) { c.Strength = csv.Stat{} }),
+			wantBaseline: testCard(func(c *csv.Card) { c.Strength = csv.Stat{} }),
+		},
+		{
+			name:          "changed differently in both",
+			sheet:         testCard(func(c *csv.Card) { c.Effect = "Sheet" }),
+			api:           testCard(func(c *csv.Card) { c.Effect = "API" }),
+			wantCard:      testCard(func(c *csv.Card) { c.Effect = "API" }),
+			wantBaseline:  testCard(nil),
+			wantConflicts: []string{"effects.0.text"},
+		},
+		{
+			name:         "traits in another order",
+			sheet:        testCard(func(c *csv.Card) { c.Traits = csv.List{"Hero", "Villain"} }),
+			api:          testCard(func(c *csv.Card) { c.Traits = csv.List{"Villain", "Hero"} }),
+			wantCard:     testCard(func(c *csv.Card) { c.Traits = csv.List{"Hero", "Villain"} }),
+			wantBaseline: testCard(func(c *csv.Card) { c.Traits = csv.List{"Hero", "Villain"} }),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Card(test.sheet, test.api, testCard(nil))
+
+			if reflect.DeepEqual(result.Card, test.wantCard) == false {
+				t.Errorf("Card = %+v, want %+v", result.Card, test.wantCard)
+			}
+			if reflect.DeepEqual(result.Baseline, test.wantBaseline) == false {
+				t.Errorf("Baseline = %+v, want %+v", result.Baseline, test.wantBaseline)
+			}
+			if reflect.DeepEqual(result.Kept, test.wantKept) == false {
+				t.Errorf("Kept = %v, want %v", result.Kept, test.wantKept)
+			}
+
+			var conflicts []string
+			for _, conflict := range result.Conflicts {
+				conflicts = append(conflicts, conflict.Field)
+			}
+			if reflect.DeepEqual(conflicts, test.wantConflicts) == false {
+				t.Errorf("Conflicts = %v, want %v", conflicts, test.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestIsChanged(t *testing.T) {
+	tests := []struct {
+		name string
+		card *csv.Card
+		want bool
+	}{
+		{"unchanged", testCard(nil), false},
+		{"title", testCard(func(c *csv.Card) { c.Title = "Changed" }), true},
+		{"stat cleared", testCard(func(c *csv.Card) { c.Strength = csv.Stat{} }), true},
+		{"stat set to 0", testCard(func(c *csv.Card) { c.Intelligence = csv.NewStat(0) }), true},
+		{"second effect", testCard(func(c *csv.Card) { c.Effect2 = "Discard a card." }), true},
+		{"image variants", testCard(func(c *csv.Card) { c.ImageVariants = map[string]string{"large.webp": "url"} }), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsChanged(test.card, testCard(nil)); got != test.want {
+				t.Errorf("IsChanged = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
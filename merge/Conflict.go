@@ -0,0 +1,55 @@
+package merge
+
+import (
+	"fmt"
+	"io"
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+)
+
+// Conflict is a field that was changed in both the sheet and the API since the
+// baseline, to different values
+type Conflict struct {
+	UID      string      `json:"uid"`
+	Field    string      `json:"field"`
+	Baseline interface{} `json:"baseline"`
+	Sheet    interface{} `json:"sheet"`
+	API      interface{} `json:"api"`
+}
+
+// Conflicts compares the sheet and the API with the baseline they were both
+// at after the last sync. Cards missing from the baseline have no conflicts,
+// the same as when syncing them
+func Conflicts(sheet []*csv.Card, api []*gql.Card, baseline map[string]*csv.Card) []*Conflict {
+	apiCards := make(map[string]*gql.Card)
+	for _, card := range api {
+		apiCards[card.UID] = card
+	}
+
+	var conflicts []*Conflict
+	for _, card := range sheet {
+		apiCard := apiCards[card.UID]
+		base := baseline[card.UID]
+		if apiCard == nil || base == nil {
+			continue
+		}
+
+		// Cards the csv can't represent aren't merged
+		current, err := apiCard.CSV()
+		if err != nil {
+			continue
+		}
+
+		conflicts = append(conflicts, Card(card, current, base).Conflicts...)
+	}
+
+	return conflicts
+}
+
+// PrintConflicts writes a line for each Conflict with the baseline, sheet and API values
+func PrintConflicts(w io.Writer, conflicts []*Conflict) {
+	for _, conflict := range conflicts {
+		fmt.Fprintf(w, "conflict %s %s: baseline %#v, sheet %#v, api %#v\n",
+			conflict.UID, conflict.Field, conflict.Baseline, conflict.Sheet, conflict.API)
+	}
+}
@@ -0,0 +1,73 @@
+package merge
+
+import (
+	"mxdb-tools/csv"
+	"sort"
+	"strconv"
+)
+
+// field is a column of the sheet, named like the fields of a diff.Diff
+type field struct {
+	path string
+	get  func(card *csv.Card) interface{}
+	copy func(dst, src *csv.Card)
+}
+
+// Image variants aren't merged since they're derived from the image URLs
+var fields = []field{
+	{"uid", func(c *csv.Card) interface{} { return c.UID }, func(d, s *csv.Card) { d.UID = s.UID }},
+	{"rarity", func(c *csv.Card) interface{} { return c.Rarity }, func(d, s *csv.Card) { d.Rarity = s.Rarity }},
+	{"number", func(c *csv.Card) interface{} { return c.Number }, func(d, s *csv.Card) { d.Number = s.Number }},
+	{"set", func(c *csv.Card) interface{} { return c.Set }, func(d, s *csv.Card) { d.Set = s.Set }},
+	{"title", func(c *csv.Card) interface{} { return c.Title }, func(d, s *csv.Card) { d.Title = s.Title }},
+	{"subtitle", func(c *csv.Card) interface{} { return c.Subtitle }, func(d, s *csv.Card) { d.Subtitle = s.Subtitle }},
+	{"type", func(c *csv.Card) interface{} { return c.Type }, func(d, s *csv.Card) { d.Type = s.Type }},
+	{"mp", func(c *csv.Card) interface{} { return c.MP }, func(d, s *csv.Card) { d.MP = s.MP }},
+	{"traits", func(c *csv.Card) interface{} { return sortedTraits(c) }, func(d, s *csv.Card) { d.Traits = s.Traits }},
+	{"stats.strength", func(c *csv.Card) interface{} { return c.Strength.Value() }, func(d, s *csv.Card) { d.Strength = s.Strength }},
+	{"stats.intelligence", func(c *csv.Card) interface{} { return c.Intelligence.Value() }, func(d, s *csv.Card) { d.Intelligence = s.Intelligence }},
+	{"stats.special", func(c *csv.Card) interface{} { return c.Special.Value() }, func(d, s *csv.Card) { d.Special = s.Special }},
+	effectField(0, "symbol"), effectField(0, "text"),
+	effectField(1, "symbol"), effectField(1, "text"),
+	effectField(2, "symbol"), effectField(2, "text"),
+	{"image.original", func(c *csv.Card) interface{} { return c.OriginalImageURL }, func(d, s *csv.Card) { d.OriginalImageURL = s.OriginalImageURL }},
+	{"image.large", func(c *csv.Card) interface{} { return c.LargeImageURL }, func(d, s *csv.Card) { d.LargeImageURL = s.LargeImageURL }},
+	{"image.medium", func(c *csv.Card) interface{} { return c.MediumImageURL }, func(d, s *csv.Card) { d.MediumImageURL = s.MediumImageURL }},
+	{"image.small", func(c *csv.Card) interface{} { return c.SmallImageURL }, func(d, s *csv.Card) { d.SmallImageURL = s.SmallImageURL }},
+	{"image.thumbnail", func(c *csv.Card) interface{} { return c.ThumbnailImageURL }, func(d, s *csv.Card) { d.ThumbnailImageURL = s.ThumbnailImageURL }},
+	{"preview.previewer", func(c *csv.Card) interface{} { return c.Previewer }, func(d, s *csv.Card) { d.Previewer = s.Previewer }},
+	{"preview.previewUrl", func(c *csv.Card) interface{} { return c.PreviewURL }, func(d, s *csv.Card) { d.PreviewURL = s.PreviewURL }},
+	{"preview.isActive", func(c *csv.Card) interface{} { return c.PreviewActive }, func(d, s *csv.Card) { d.PreviewActive = s.PreviewActive }},
+}
+
+// effectField is one column of the i-th pair of effect columns
+func effectField(i int, name string) field {
+	get := func(c *csv.Card) interface{} {
+		if name == "symbol" {
+			return c.EffectAt(i).Symbol
+		}
+		return c.EffectAt(i).Text
+	}
+	copy := func(d, s *csv.Card) {
+		effect := d.EffectAt(i)
+		if name == "symbol" {
+			effect.Symbol = s.EffectAt(i).Symbol
+		} else {
+			effect.Text = s.EffectAt(i).Text
+		}
+		d.SetEffectAt(i, effect)
+	}
+
+	return field{"effects." + strconv.Itoa(i) + "." + name, get, copy}
+}
+
+// sortedTraits returns the card's traits in order of name, since the API doesn't keep the sheet's order
+func sortedTraits(c *csv.Card) []string {
+	if len(c.Traits) == 0 {
+		return nil
+	}
+
+	traits := append([]string(nil), c.Traits...)
+	sort.Strings(traits)
+	return traits
+}
@@ -0,0 +1,279 @@
+package plan
+
+import (
+	"fmt"
+	"log"
+	"mxdb-tools/csv"
+	"mxdb-tools/diff"
+	"mxdb-tools/gql"
+	"mxdb-tools/image"
+	"os"
+	"strings"
+)
+
+// Apply generates images and sends every mutation in the Plan through the Client,
+// BatchSize mutations per request. Failures are recorded in the Report instead of
+// stopping the run
+func (p *Plan) Apply(client *gql.Client) (*Report, error) {
+	if err := image.CreateDirectories(); err != nil {
+		return nil, err
+	}
+
+	// Cards whose new images couldn't be created aren't updated
+	skip := make(map[string]bool)
+
+	// Cards with missing or stale images are built, and the originals of the
+	// rest are checked for changes behind the same URL
+	summary := image.Build(p.imageCards(), p.Concurrency)
+	summary.Print(os.Stderr)
+
+	report := newReport(summary)
+	report.Unchanged = len(p.Cards) - len(p.Creates) - len(p.Updates) - len(p.Refused)
+	report.Conflicts = p.Conflicts()
+
+	for uid, err := range p.Refused {
+		log.Println("Not syncing", uid, err)
+		report.addError(uid, err)
+		report.Skipped++
+	}
+
+	for _, update := range p.Updates {
+		if update.RegenerateImages && summary.Errors[update.Card.UID] != nil {
+			skip[update.Card.UID] = true
+		}
+	}
+
+	if p.Uploader != nil {
+		for uid, err := range p.upload() {
+			log.Println("Unable to upload images:", uid, err)
+			report.addError(uid, fmt.Errorf("upload: %s", err))
+			skip[uid] = true
+		}
+	}
+
+	// Creates and updates are sent in batches, so a card's errors are only known once they're all sent
+	batch := client.NewBatch(p.BatchSize)
+	var updated []string
+	var created []string
+
+	for _, update := range p.Updates {
+		uid := update.Card.UID
+		if skip[uid] {
+			report.Skipped++
+			continue
+		}
+
+		mutations, err := update.mutations(client)
+		if err != nil {
+			log.Println(uid, err)
+			report.addError(uid, err)
+			report.Failed++
+			continue
+		}
+		if len(mutations) == 0 {
+			// Everything that differs was kept from the API or is a conflict
+			report.Unchanged++
+			continue
+		}
+
+		logDiff(update.Diff())
+		for _, m := range mutations {
+			batch.Add(uid, m)
+		}
+		updated = append(updated, uid)
+	}
+
+	for _, card := range p.Creates {
+		if skip[card.UID] {
+			report.Skipped++
+			continue
+		}
+
+		m, err := client.CreateCardMutation(card)
+		if err != nil {
+			log.Println(card.UID, err)
+			report.addError(card.UID, fmt.Errorf("create: %s", err))
+			report.Failed++
+			continue
+		}
+
+		logDiff(diff.Card(card, nil))
+		batch.Add(card.UID, m)
+		created = append(created, card.UID)
+	}
+
+	failed := make(map[string]bool)
+	for _, result := range batch.Send() {
+		if result.Err != nil {
+			log.Println(result.Key, result.Mutation.Name, result.Err)
+			report.addError(result.Key, fmt.Errorf("%s: %s", result.Mutation.Name, result.Err))
+			failed[result.Key] = true
+		}
+	}
+
+	for _, uid := range updated {
+		if failed[uid] {
+			report.Failed++
+		} else {
+			log.Printf("Card updated: %s", uid)
+			report.Updated++
+		}
+	}
+
+	for _, uid := range created {
+		if failed[uid] {
+			report.Failed++
+		} else {
+			log.Printf("Card created: %s", uid)
+			report.Created++
+		}
+	}
+
+	for _, orphan := range p.Orphans {
+		p.prune(client, orphan, report)
+	}
+	for _, card := range p.APIOnly {
+		if p.Prune != "" {
+			log.Println("Not pruning", card.UID, "- added in the API since the last sync")
+		}
+		report.APIOnly++
+	}
+
+	if p.Baseline != nil {
+		p.saveBaseline(report)
+	}
+
+	return report, nil
+}
+
+func (p *Plan) prune(client *gql.Client, orphan *gql.Card, report *Report) {
+	switch p.Prune {
+	case PruneDelete:
+		_, err := client.DeleteCard(orphan)
+		if err != nil {
+			log.Println(orphan.UID, err)
+			report.addError(orphan.UID, fmt.Errorf("delete: %s", err))
+			// Nodes left behind are reported, but the card itself is gone
+			if _, ok := err.(*gql.LeftBehindError); ok == false {
+				report.Failed++
+				return
+			}
+		}
+		log.Printf("Card deleted: %s", orphan.UID)
+		report.Deleted++
+
+		if err := image.RemoveAll(&csv.Card{UID: orphan.UID}); err != nil {
+			log.Println(err)
+			report.addError(orphan.UID, fmt.Errorf("images: %s", err))
+		}
+	case PruneDeactivatePreview:
+		if orphan.Preview.ID == "" || orphan.Preview.IsActive == false {
+			log.Printf("No active preview to deactivate: %s", orphan.UID)
+			return
+		}
+
+		_, err := client.DeactivatePreview(&orphan.Preview)
+		if err != nil {
+			log.Println(orphan.UID, err)
+			report.addError(orphan.UID, fmt.Errorf("deactivate: %s", err))
+			report.Failed++
+			return
+		}
+		log.Printf("Preview deactivated: %s", orphan.UID)
+		report.PreviewsDeactivated++
+	}
+}
+
+// imageCards are the cards whose images are generated or regenerated, and
+// every other card built before, whose original is downloaded again if it changed
+func (p *Plan) imageCards() []*csv.Card {
+	cards := append([]*csv.Card(nil), p.Images...)
+	included := make(map[string]bool)
+	for _, card := range p.Images {
+		included[card.UID] = true
+	}
+
+	// Updates hold the merged card, which may keep the API's image
+	merged := make(map[string]*csv.Card)
+	for _, update := range p.Updates {
+		merged[update.Card.UID] = update.Card
+		if update.RegenerateImages {
+			cards = append(cards, update.Card)
+			included[update.Card.UID] = true
+		}
+	}
+
+	skipped := make(map[string]bool)
+	for _, card := range p.Removed {
+		skipped[card.UID] = true
+	}
+
+	for _, card := range p.Cards {
+		uid := card.UID
+		if included[uid] || skipped[uid] || p.Refused[uid] != nil || image.IsBuilt(uid) == false {
+			continue
+		}
+		if merged[uid] != nil {
+			card = merged[uid]
+		}
+		cards = append(cards, card)
+		included[uid] = true
+	}
+
+	return cards
+}
+
+// mutations builds every Mutation needed to update the card
+func (update *Update) mutations(client *gql.Client) ([]*gql.Mutation, error) {
+	var mutations []*gql.Mutation
+	card := update.Card
+	currentCard := update.Current
+
+	add := func(m *gql.Mutation, err error) error {
+		if err == nil {
+			mutations = append(mutations, m)
+		}
+		return err
+	}
+
+	if len(update.Preview) != 0 {
+		if err := add(client.UpdatePreviewMutation(&currentCard.Preview, card)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(update.Image) != 0 {
+		if currentCard.Image.IsEmpty() {
+			if err := add(client.CreateImageMutation(currentCard, card)); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := add(client.UpdateImageMutation(&currentCard.Image, card)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(update.Fields) != 0 {
+		if err := add(client.UpdateCardMutation(currentCard, card)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(update.Effects) != 0 {
+		effects, err := client.SetCardEffectsMutations(currentCard, card)
+		if err != nil {
+			return nil, err
+		}
+		mutations = append(mutations, effects...)
+	}
+
+	return mutations, nil
+}
+
+// logDiff logs every field that is about to change
+func logDiff(d *diff.Diff) {
+	var b strings.Builder
+	d.WriteText(&b, false)
+	log.Print(b.String())
+}
@@ -0,0 +1,130 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"mxdb-tools/gql"
+	"mxdb-tools/image"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testAPI answers every mutation with the id it was sent, or with an error
+// when the id starts with "fail". Each mutation is recorded as "<name> <id>"
+type testAPI struct {
+	mu        sync.Mutex
+	mutations []string
+}
+
+func (api *testAPI) client(t *testing.T) *gql.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+			return
+		}
+
+		name := strings.TrimPrefix(req.Query, "mutation ")
+		name = name[:strings.Index(name, "(")]
+		id := req.Variables["id"]
+
+		api.mu.Lock()
+		api.mutations = append(api.mutations, name+" "+id)
+		api.mu.Unlock()
+
+		if strings.HasPrefix(id, "fail") {
+			fmt.Fprintf(w, `{"data": null, "errors": [{"message": "Unable to %s", "path": ["node"]}]}`, name)
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"node": {"id": %q}}}`, id)
+	}))
+	t.Cleanup(server.Close)
+
+	return gql.NewClient("token", gql.WithEndpoint(server.URL), gql.WithRetries(0, time.Millisecond))
+}
+
+func orphans() []*gql.Card {
+	return []*gql.Card{
+		{
+			ID:      "card1",
+			UID:     "A-001",
+			Effects: gql.Effects{{ID: "effect1"}},
+			Image:   gql.Image{ID: "image1"},
+			Preview: gql.Preview{ID: "preview1", IsActive: true},
+		},
+		{ID: "card2", UID: "A-002", Preview: gql.Preview{ID: "fail-preview2", IsActive: true}},
+		{ID: "fail-card3", UID: "A-003", Preview: gql.Preview{ID: "preview3"}},
+	}
+}
+
+func applyPrune(t *testing.T, prune string) (*Report, []string) {
+	image.SetBaseDir(t.TempDir())
+
+	api := &testAPI{}
+	p := &Plan{
+		Orphans: orphans(),
+		APIOnly: []*gql.Card{{ID: "card4", UID: "A-004"}},
+		Refused: make(map[string]error),
+		Prune:   prune,
+	}
+
+	report, err := p.Apply(api.client(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return report, api.mutations
+}
+
+func TestApplyPruneDelete(t *testing.T) {
+	report, mutations := applyPrune(t, PruneDelete)
+
+	want := []string{
+		"DeleteCard card1", "DeleteEffect effect1", "DeleteImage image1", "DeletePreview preview1",
+		"DeleteCard card2", "DeletePreview fail-preview2",
+		"DeleteCard fail-card3",
+	}
+	if reflect.DeepEqual(mutations, want) == false {
+		t.Errorf("Apply() sent %v, want %v", mutations, want)
+	}
+
+	// A-002 is gone even though its preview was left behind
+	if report.Deleted != 2 || report.Failed != 1 || report.APIOnly != 1 {
+		t.Errorf("Apply() = %+v, want 2 deleted, 1 failed and 1 only in the api", report)
+	}
+	if len(report.Errors["A-002"]) != 1 || len(report.Errors["A-003"]) != 1 || report.HasFailures() == false {
+		t.Errorf("Errors = %v, want the preview left behind by A-002 and the failed A-003", report.Errors)
+	}
+}
+
+func TestApplyPruneDeactivatePreview(t *testing.T) {
+	report, mutations := applyPrune(t, PruneDeactivatePreview)
+
+	// A-003's preview is already inactive
+	want := []string{"DeactivatePreview preview1", "DeactivatePreview fail-preview2"}
+	if reflect.DeepEqual(mutations, want) == false {
+		t.Errorf("Apply() sent %v, want %v", mutations, want)
+	}
+	if report.PreviewsDeactivated != 1 || report.Failed != 1 || report.Deleted != 0 {
+		t.Errorf("Apply() = %+v, want 1 deactivated and 1 failed", report)
+	}
+}
+
+func TestApplyWithoutPrune(t *testing.T) {
+	report, mutations := applyPrune(t, "")
+
+	if len(mutations) != 0 {
+		t.Errorf("Apply() sent %v without --prune", mutations)
+	}
+	if report.HasFailures() || report.APIOnly != 1 {
+		t.Errorf("Apply() = %+v", report)
+	}
+}
@@ -0,0 +1,129 @@
+package plan
+
+import (
+	"log"
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"mxdb-tools/merge"
+)
+
+// Merge compares every card with the Baseline from the last sync. Changes only
+// made in the API are left alone, and fields changed on both sides are flagged
+// as conflicts instead of being overwritten. Cards that aren't in the Baseline
+// are synced from the sheet as before, and cards only in the API are only
+// pruned if they were in the Baseline
+func (p *Plan) Merge(baseline *merge.Baseline) {
+	p.Baseline = baseline
+	p.baselines = make(map[string]*csv.Card)
+
+	var updates []*Update
+	for _, update := range p.Updates {
+		base := baseline.Cards[update.Card.UID]
+		if base == nil {
+			updates = append(updates, update)
+			continue
+		}
+
+		// Build refuses the cards that can't be converted
+		current, err := update.Current.CSV()
+		if err != nil {
+			updates = append(updates, update)
+			continue
+		}
+
+		result := merge.Card(update.Card, current, base)
+		p.baselines[update.Card.UID] = result.Baseline
+
+		update.Card = result.Card
+		update.Kept = result.Kept
+		update.Conflicts = result.Conflicts
+		update.Preview = update.Current.Preview.Changes(result.Card)
+		update.Image = update.Current.Image.Changes(result.Card)
+		update.Effects = update.Current.Effects.Changes(result.Card)
+		update.Fields = update.Current.Changes(result.Card)
+
+		if update.IsEmpty() == false || len(update.Kept) != 0 || len(update.Conflicts) != 0 {
+			updates = append(updates, update)
+		}
+	}
+	p.Updates = updates
+
+	var creates []*csv.Card
+	for _, card := range p.Creates {
+		if baseline.Cards[card.UID] == nil {
+			creates = append(creates, card)
+			continue
+		}
+
+		// The card was synced before, so it has been deleted in the API since
+		p.Removed = append(p.Removed, card)
+	}
+	p.Creates = creates
+
+	var orphans []*gql.Card
+	for _, orphan := range p.Orphans {
+		if baseline.Cards[orphan.UID] != nil {
+			orphans = append(orphans, orphan)
+			continue
+		}
+
+		// The card has never been in the sheet, so it was added in the API
+		p.APIOnly = append(p.APIOnly, orphan)
+	}
+	p.Orphans = orphans
+}
+
+// Conflicts lists every field changed in both the sheet and the API since the last sync
+func (p *Plan) Conflicts() []*merge.Conflict {
+	var conflicts []*merge.Conflict
+	for _, update := range p.Updates {
+		conflicts = append(conflicts, update.Conflicts...)
+	}
+
+	for _, card := range p.Removed {
+		if merge.IsChanged(card, p.Baseline.Cards[card.UID]) {
+			conflicts = append(conflicts, &merge.Conflict{
+				UID:      card.UID,
+				Field:    "card",
+				Baseline: "synced",
+				Sheet:    "changed",
+				API:      "deleted",
+			})
+		}
+	}
+
+	return conflicts
+}
+
+// saveBaseline records the cards that were synced without errors, then saves the Baseline
+func (p *Plan) saveBaseline(report *Report) {
+	removed := make(map[string]bool)
+	for _, card := range p.Removed {
+		removed[card.UID] = true
+	}
+
+	for _, card := range p.Cards {
+		if removed[card.UID] || len(report.Errors[card.UID]) != 0 {
+			continue
+		}
+
+		if base := p.baselines[card.UID]; base != nil {
+			p.Baseline.Cards[card.UID] = base
+		} else {
+			p.Baseline.Cards[card.UID] = card
+		}
+	}
+
+	if p.Prune == PruneDelete {
+		for _, orphan := range p.Orphans {
+			if len(report.Errors[orphan.UID]) == 0 {
+				delete(p.Baseline.Cards, orphan.UID)
+			}
+		}
+	}
+
+	if err := p.Baseline.Save(); err != nil {
+		log.Println("Unable to save the baseline:", err)
+		report.addError("baseline", err)
+	}
+}
@@ -0,0 +1,83 @@
+package plan
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"mxdb-tools/merge"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := func(uid string, title string) *csv.Card {
+		return &csv.Card{UID: uid, Title: title, Type: "Event"}
+	}
+	api := func(uid string, title string) *gql.Card {
+		return &gql.Card{UID: uid, Title: title, Type: "Event"}
+	}
+
+	baseline := &merge.Baseline{Cards: map[string]*csv.Card{
+		"sheet":    base("sheet", "Base"),
+		"api":      base("api", "Base"),
+		"conflict": base("conflict", "Base"),
+		"removed":  base("removed", "Base"),
+		"orphan":   base("orphan", "Base"),
+	}}
+	sheet := []*csv.Card{
+		base("sheet", "Sheet"),
+		base("api", "Base"),
+		base("conflict", "Sheet"),
+		base("removed", "Base"),
+		base("new", "New"),
+	}
+	current := []*gql.Card{
+		api("sheet", "Base"),
+		api("api", "API"),
+		api("conflict", "API"),
+		api("orphan", "Base"),
+		api("added", "Added"),
+	}
+
+	p := Build(sheet, current)
+	p.Merge(baseline)
+
+	updates := make(map[string]*Update)
+	for _, update := range p.Updates {
+		updates[update.Card.UID] = update
+	}
+
+	if update := updates["sheet"]; update == nil || update.Card.Title != "Sheet" {
+		t.Errorf("The sheet's change to %q isn't synced", "sheet")
+	}
+	if update := updates["api"]; update == nil || update.IsEmpty() == false || reflect.DeepEqual(update.Kept, []string{"title"}) == false {
+		t.Errorf("The API's change to %q isn't kept", "api")
+	}
+	if update := updates["conflict"]; update == nil || update.IsEmpty() == false || len(update.Conflicts) != 1 {
+		t.Errorf("The change to %q on both sides isn't a conflict", "conflict")
+	}
+
+	if len(p.Creates) != 1 || p.Creates[0].UID != "new" {
+		t.Errorf("Creates = %v, want only the card new in the sheet", p.Creates)
+	}
+	if len(p.Removed) != 1 || p.Removed[0].UID != "removed" {
+		t.Errorf("Removed = %v, want only the card deleted in the API", p.Removed)
+	}
+	if len(p.Orphans) != 1 || p.Orphans[0].UID != "orphan" {
+		t.Errorf("Orphans = %v, want only the card removed from the sheet", p.Orphans)
+	}
+	if len(p.APIOnly) != 1 || p.APIOnly[0].UID != "added" {
+		t.Errorf("APIOnly = %v, want only the card added in the API", p.APIOnly)
+	}
+}
+
+func TestMergeEmptyBaseline(t *testing.T) {
+	p := Build(nil, []*gql.Card{{UID: "1-009", Title: "Orphan"}})
+	p.Merge(&merge.Baseline{Cards: make(map[string]*csv.Card)})
+
+	if len(p.Orphans) != 0 || len(p.APIOnly) != 1 {
+		t.Errorf("Orphans = %v and APIOnly = %v, want a card that was never synced left alone", p.Orphans, p.APIOnly)
+	}
+	for _, d := range p.Diffs() {
+		t.Errorf("Diffs has %s %s, want none", d.Op, d.UID)
+	}
+}
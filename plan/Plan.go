@@ -0,0 +1,203 @@
+package plan
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/diff"
+	"mxdb-tools/gql"
+	"mxdb-tools/image"
+	"mxdb-tools/merge"
+	"mxdb-tools/upload"
+)
+
+const (
+	// PruneDelete deletes cards that are no longer in the csv
+	PruneDelete = "delete"
+	// PruneDeactivatePreview marks the Preview of cards that are no longer in the
+	// csv as inactive. The cards themselves are left alone
+	PruneDeactivatePreview = "deactivate-preview"
+)
+
+// Plan is every change needed to bring the API in line with the csv
+type Plan struct {
+	Cards   []*csv.Card
+	Creates []*csv.Card
+	Updates []*Update
+	Images  []*csv.Card
+	Orphans []*gql.Card
+
+	// Refused are cards that can't be synced, by UID. Either the csv can't
+	// represent the card in the API, and syncing it would drop what the csv is
+	// missing, or the card's traits or stats don't exist in the API
+	Refused map[string]error
+
+	// Removed are cards that were deleted in the API since the last sync
+	Removed []*csv.Card
+	// APIOnly are cards that were added in the API since the last sync. Unlike
+	// Orphans they were never in the sheet, so they aren't pruned
+	APIOnly  []*gql.Card
+	Baseline *merge.Baseline
+	// baselines are the next Baseline of merged cards, used once they're synced
+	baselines map[string]*csv.Card
+
+	Prune       string
+	Concurrency int
+	BatchSize   int
+	Uploader    upload.Uploader
+}
+
+// Update is the set of changes for a card that already exists in the API
+type Update struct {
+	Card             *csv.Card
+	Current          *gql.Card
+	Preview          []gql.Change
+	Image            []gql.Change
+	Effects          []gql.Change
+	Fields           []gql.Change
+	RegenerateImages bool
+
+	// Kept are fields only changed in the API, which are left alone
+	Kept      []string
+	Conflicts []*merge.Conflict
+}
+
+// IsEmpty returns true if the Update has nothing to change
+func (update *Update) IsEmpty() bool {
+	return (len(update.Preview) == 0 &&
+		len(update.Image) == 0 &&
+		len(update.Effects) == 0 &&
+		len(update.Fields) == 0 &&
+		update.RegenerateImages == false)
+}
+
+// Diff lists the changes to the card field by field
+func (update *Update) Diff() *diff.Diff {
+	d := diff.New(update.Card.UID, diff.Update)
+	d.Add("", update.Fields)
+	d.Add("effects", update.Effects)
+	d.Add("image", update.Image)
+	d.Add("preview", update.Preview)
+	return d
+}
+
+// Diffs lists every card that differs between the csv and the API, including
+// the cards that aren't in the csv
+func (p *Plan) Diffs() []*diff.Diff {
+	var diffs []*diff.Diff
+	for _, card := range p.Creates {
+		diffs = append(diffs, diff.Card(card, nil))
+	}
+	for _, update := range p.Updates {
+		d := update.Diff()
+		if d.IsEmpty() == false {
+			diffs = append(diffs, d)
+		}
+	}
+	for _, orphan := range p.Orphans {
+		diffs = append(diffs, diff.Card(nil, orphan))
+	}
+	return diffs
+}
+
+// Build compares the csv cards against the cards in the API
+func Build(cards []*csv.Card, gqlCards []*gql.Card) *Plan {
+	// TODO: Should this be the output of loadGraphQL?
+	currentCards := make(map[string]*gql.Card)
+	for _, gqlCard := range gqlCards {
+		currentCards[gqlCard.UID] = gqlCard
+	}
+
+	csvUIDs := make(map[string]bool)
+	for _, card := range cards {
+		csvUIDs[card.UID] = true
+	}
+
+	p := &Plan{Cards: cards, Refused: make(map[string]error)}
+	for _, gqlCard := range gqlCards {
+		if csvUIDs[gqlCard.UID] == false {
+			p.Orphans = append(p.Orphans, gqlCard)
+		}
+	}
+
+	for _, card := range cards {
+		currentCard := currentCards[card.UID]
+
+		if currentCard == nil {
+			p.Creates = append(p.Creates, card)
+			if image.IsStale(card) {
+				p.Images = append(p.Images, card)
+			}
+			continue
+		}
+
+		if err := currentCard.CheckCSV(); err != nil {
+			p.Refused[card.UID] = err
+			continue
+		}
+
+		update := &Update{
+			Card:    card,
+			Current: currentCard,
+			Preview: currentCard.Preview.Changes(card),
+			Image:   currentCard.Image.Changes(card),
+			Effects: currentCard.Effects.Changes(card),
+			Fields:  currentCard.Changes(card),
+		}
+
+		if image.IsStale(card) {
+			if currentCard.Image.IsEmpty() == false && card.OriginalImageURL != currentCard.Image.Original {
+				update.RegenerateImages = true
+			} else {
+				p.Images = append(p.Images, card)
+			}
+		}
+
+		if update.IsEmpty() == false {
+			p.Updates = append(p.Updates, update)
+		}
+	}
+
+	return p
+}
+
+// Resolve refuses the cards to create or update whose traits or stats aren't
+// in the lookups, since their mutations would fail
+func (p *Plan) Resolve(lookups *gql.Lookups) {
+	var creates []*csv.Card
+	for _, card := range p.Creates {
+		if err := resolve(lookups, card); err != nil {
+			p.Refused[card.UID] = err
+			continue
+		}
+		creates = append(creates, card)
+	}
+	p.Creates = creates
+
+	var updates []*Update
+	for _, update := range p.Updates {
+		if len(update.Fields) != 0 {
+			if err := resolve(lookups, update.Card); err != nil {
+				p.Refused[update.Card.UID] = err
+				continue
+			}
+		}
+		updates = append(updates, update)
+	}
+	p.Updates = updates
+
+	var images []*csv.Card
+	for _, card := range p.Images {
+		if p.Refused[card.UID] == nil {
+			images = append(images, card)
+		}
+	}
+	p.Images = images
+}
+
+func resolve(lookups *gql.Lookups, card *csv.Card) error {
+	if _, err := lookups.StatIDs(card); err != nil {
+		return err
+	}
+
+	_, err := lookups.TraitIDs(card)
+	return err
+}
@@ -0,0 +1,46 @@
+package plan
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"reflect"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	lookups := gql.NewLookups(
+		&gql.StatRanks{Strength: []*gql.Stats{{ID: "s3", Rank: 3}}},
+		[]*gql.Trait{{ID: "t1", Name: "Hero"}},
+	)
+
+	known := &csv.Card{UID: "1", Type: "Battle", Traits: csv.List{"Hero"}, Strength: csv.NewStat(3)}
+	unknownTrait := &csv.Card{UID: "2", Type: "Battle", Traits: csv.List{"Villain"}, Strength: csv.NewStat(3)}
+	unknownRank := &csv.Card{UID: "3", Type: "Battle", Strength: csv.NewStat(4)}
+	imageOnly := &csv.Card{UID: "4", Type: "Battle", Traits: csv.List{"Villain"}}
+	changed := &csv.Card{UID: "5", Type: "Battle", Traits: csv.List{"Villain"}, Strength: csv.NewStat(3)}
+
+	p := &Plan{
+		Creates: []*csv.Card{known, unknownTrait, unknownRank},
+		Updates: []*Update{
+			{Card: imageOnly, Image: []gql.Change{{Field: "large"}}},
+			{Card: changed, Fields: []gql.Change{{Field: "traits"}}},
+		},
+		Images:  []*csv.Card{known, unknownTrait},
+		Refused: make(map[string]error),
+	}
+	p.Resolve(lookups)
+
+	if reflect.DeepEqual(p.Creates, []*csv.Card{known}) == false {
+		t.Errorf("Creates = %v, want only card 1", p.Creates)
+	}
+	if len(p.Updates) != 1 || p.Updates[0].Card != imageOnly {
+		t.Errorf("Updates = %v, want only the update of card 4, whose fields are unchanged", p.Updates)
+	}
+	if reflect.DeepEqual(p.Images, []*csv.Card{known}) == false {
+		t.Errorf("Images = %v, want only card 1", p.Images)
+	}
+
+	if refused := sortedUIDs(p.Refused); reflect.DeepEqual(refused, []string{"2", "3", "5"}) == false {
+		t.Errorf("Refused = %v, want cards 2, 3 and 5", refused)
+	}
+}
@@ -0,0 +1,73 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"mxdb-tools/diff"
+	"sort"
+)
+
+// Print writes a human readable version of the Plan
+func (p *Plan) Print(w io.Writer) {
+	for _, card := range p.Creates {
+		diff.Card(card, nil).WriteText(w, false)
+	}
+
+	for _, update := range p.Updates {
+		update.Diff().WriteText(w, false)
+		if len(update.Image) != 0 && update.Current.Image.IsEmpty() {
+			fmt.Fprintln(w, "  image: create")
+		}
+		if update.RegenerateImages {
+			fmt.Fprintln(w, "  images: regenerate")
+		}
+		for _, field := range update.Kept {
+			fmt.Fprintf(w, "  = %s: changed in the api, kept\n", field)
+		}
+		for _, conflict := range update.Conflicts {
+			fmt.Fprintf(w, "  ! %s: conflict, baseline %#v, sheet %#v, api %#v\n", conflict.Field, conflict.Baseline, conflict.Sheet, conflict.API)
+		}
+	}
+
+	for _, card := range p.Removed {
+		fmt.Fprintf(w, "removed %s %#v: deleted in the api since the last sync, not recreated\n", card.UID, card.Title)
+	}
+
+	for _, uid := range sortedUIDs(p.Refused) {
+		fmt.Fprintf(w, "refused %s: %s\n", uid, p.Refused[uid])
+	}
+
+	for _, card := range p.Images {
+		fmt.Fprintf(w, "images %s: generate\n", card.UID)
+	}
+
+	for _, card := range p.APIOnly {
+		fmt.Fprintf(w, "api only %s %#v: added in the api since the last sync, not pruned\n", card.UID, card.Title)
+	}
+
+	for _, orphan := range p.Orphans {
+		switch p.Prune {
+		case PruneDelete:
+			fmt.Fprintf(w, "delete %s %#v\n", orphan.UID, orphan.Title)
+		case PruneDeactivatePreview:
+			if orphan.Preview.ID == "" || orphan.Preview.IsActive == false {
+				fmt.Fprintf(w, "orphan %s %#v: not in csv, no active preview\n", orphan.UID, orphan.Title)
+			} else {
+				fmt.Fprintf(w, "deactivate preview %s %#v\n", orphan.UID, orphan.Title)
+			}
+		default:
+			fmt.Fprintf(w, "orphan %s %#v: not in csv\n", orphan.UID, orphan.Title)
+		}
+	}
+
+	fmt.Fprintf(w, "%d to create, %d to update, %d image sets to generate, %d not in csv, %d only in the api, %d conflicts, %d refused\n", len(p.Creates), len(p.Updates), len(p.Images), len(p.Orphans), len(p.APIOnly), len(p.Conflicts()), len(p.Refused))
+}
+
+func sortedUIDs(m map[string]error) []string {
+	var uids []string
+	for uid := range m {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+	return uids
+}
@@ -0,0 +1,73 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mxdb-tools/image"
+	"mxdb-tools/merge"
+)
+
+// Report is the outcome of applying a Plan, with every error by card UID.
+// Error is set instead when the run stopped before the Plan was applied
+type Report struct {
+	Error               string              `json:"error,omitempty"`
+	Created             int                 `json:"created"`
+	Updated             int                 `json:"updated"`
+	Unchanged           int                 `json:"unchanged"`
+	Skipped             int                 `json:"skipped"`
+	Failed              int                 `json:"failed"`
+	Deleted             int                 `json:"deleted"`
+	PreviewsDeactivated int                 `json:"previewsDeactivated"`
+	APIOnly             int                 `json:"apiOnly"`
+	Images              ImagesReport        `json:"images"`
+	Errors              map[string][]string `json:"errors"`
+	Conflicts           []*merge.Conflict   `json:"conflicts"`
+}
+
+// ImagesReport counts the cards whose images were built
+type ImagesReport struct {
+	Total     int `json:"total"`
+	Built     int `json:"built"`
+	Generated int `json:"generated"`
+	Failed    int `json:"failed"`
+}
+
+func newReport(summary *image.Summary) *Report {
+	report := &Report{
+		Images: ImagesReport{
+			Total:     summary.Total,
+			Built:     summary.Built,
+			Generated: summary.Generated,
+			Failed:    len(summary.Errors),
+		},
+		Errors: make(map[string][]string),
+	}
+
+	for uid, err := range summary.Errors {
+		report.addError(uid, fmt.Errorf("images: %s", err))
+	}
+
+	return report
+}
+
+// FailedReport is the Report of a run that stopped with err
+func FailedReport(err error) *Report {
+	return &Report{Error: err.Error(), Errors: make(map[string][]string)}
+}
+
+func (report *Report) addError(uid string, err error) {
+	report.Errors[uid] = append(report.Errors[uid], err.Error())
+}
+
+// HasFailures returns true if anything in the run failed or needs to be resolved by hand
+func (report *Report) HasFailures() bool {
+	return report.Error != "" || len(report.Errors) != 0 || len(report.Conflicts) != 0
+}
+
+// Write encodes the Report as JSON
+func (report *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
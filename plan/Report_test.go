@@ -0,0 +1,78 @@
+package plan
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mxdb-tools/image"
+	"mxdb-tools/merge"
+	"reflect"
+	"testing"
+)
+
+func TestNewReport(t *testing.T) {
+	report := newReport(&image.Summary{
+		Total:     3,
+		Built:     2,
+		Generated: 1,
+		Errors:    map[string]error{"A-003": errors.New("Unable to download")},
+	})
+
+	if report.Images != (ImagesReport{Total: 3, Built: 2, Generated: 1, Failed: 1}) {
+		t.Errorf("Images = %+v", report.Images)
+	}
+	if reflect.DeepEqual(report.Errors, map[string][]string{"A-003": {"images: Unable to download"}}) == false {
+		t.Errorf("Errors = %v", report.Errors)
+	}
+	if report.HasFailures() == false {
+		t.Error("HasFailures() = false with an image error")
+	}
+}
+
+func TestReportHasFailures(t *testing.T) {
+	tests := []struct {
+		name   string
+		report *Report
+		want   bool
+	}{
+		{"nothing failed", &Report{Created: 1, Updated: 2, Skipped: 1, APIOnly: 1, Errors: map[string][]string{}}, false},
+		{"card error", &Report{Errors: map[string][]string{"A-001": {"create: failed"}}}, true},
+		{"conflict", &Report{Conflicts: []*merge.Conflict{{UID: "A-001"}}}, true},
+		{"stopped", FailedReport(errors.New("Unable to fetch cards")), true},
+	}
+
+	for _, test := range tests {
+		if got := test.report.HasFailures(); got != test.want {
+			t.Errorf("HasFailures() = %t for %s, want %t", got, test.name, test.want)
+		}
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	report := FailedReport(errors.New("Unable to fetch cards"))
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var written map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &written); err != nil {
+		t.Fatal(err)
+	}
+	if written["error"] != "Unable to fetch cards" || written["created"] != float64(0) {
+		t.Errorf("Write() = %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := (&Report{Updated: 1, Errors: map[string][]string{}}).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	written = nil
+	if err := json.Unmarshal(buf.Bytes(), &written); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := written["error"]; ok || written["updated"] != float64(1) {
+		t.Errorf("Write() = %s, want no error", buf.String())
+	}
+}
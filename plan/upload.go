@@ -0,0 +1,40 @@
+package plan
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/upload"
+	"sync"
+)
+
+// upload publishes the images of every card, since any of them may have
+// been rebuilt. Unchanged files are skipped by the Uploader. Errors are
+// returned by card UID
+func (p *Plan) upload() map[string]error {
+	concurrency := p.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(map[string]error)
+	sem := make(chan struct{}, concurrency)
+
+	for _, card := range p.Cards {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(card *csv.Card) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := upload.Card(p.Uploader, card); err != nil {
+				mu.Lock()
+				errs[card.UID] = err
+				mu.Unlock()
+			}
+		}(card)
+	}
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+var snapshotOut string
+
+func runSnapshot(args []string) int {
+	ok := parseFlags("snapshot", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&snapshotOut, "out", "", "File to save the snapshot to, or - for stdout. Defaults to a timestamped file")
+	})
+	if ok == false {
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		log.Println(err)
+		return exitUsage
+	}
+
+	snapshot, err := client.FetchSnapshot()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	if snapshotOut == "-" {
+		return printJSON(snapshot)
+	}
+
+	path := snapshotOut
+	if path == "" {
+		path = fmt.Sprintf("snapshot-%s.json", snapshot.CreatedAt.Format("20060102T150405Z"))
+	}
+
+	if err := snapshot.Write(path); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	fmt.Fprintf(os.Stderr, "Saved %d cards to %s\n", len(snapshot.Cards), path)
+
+	return exitOK
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"mxdb-tools/gql"
+	"mxdb-tools/image"
+	"mxdb-tools/merge"
+	"mxdb-tools/plan"
+	"mxdb-tools/upload"
+	"os"
+	"time"
+)
+
+var dropboxDir string
+var dryRun bool
+var prune string
+var uploadTo string
+var uploadURL string
+var uploadEndpoint string
+var reportPath string
+var batchSize int
+var snapshotPath string
+var baselinePath string
+
+// planFlags are shared by sync and diff, since they change what the plan contains
+func planFlags(flags *flag.FlagSet) {
+	flags.StringVar(&prune, "prune", "", "Prune cards that are no longer in the csv: delete them, or deactivate-preview to only mark their preview inactive")
+	flags.StringVar(&uploadTo, "upload", "", "Upload renditions to s3://bucket/prefix or a local directory and use their URLs")
+	flags.StringVar(&uploadURL, "upload-url", "", "Public base URL the uploaded renditions are served from")
+	flags.StringVar(&uploadEndpoint, "upload-endpoint", "s3.amazonaws.com", "S3 compatible endpoint used by --upload")
+	flags.StringVar(&snapshotPath, "snapshot", "", "Compare with a file saved by the snapshot command instead of the live API")
+	flags.StringVar(&baselinePath, "baseline", "baseline.csv", "File the cards are recorded in after each sync, to leave changes made in the API alone. Empty to always overwrite the API")
+}
+
+func runSync(args []string) int {
+	ok := parseFlags("sync", args, func(flags *flag.FlagSet) {
+		planFlags(flags)
+		flags.StringVar(&dropboxDir, "dropbox", "", "Dropbox directory where large images are copied")
+		flags.BoolVar(&dryRun, "dry-run", false, "Print the changes that would be made without making them")
+		flags.IntVar(&batchSize, "batch-size", 25, "Number of mutations sent in each request")
+		flags.StringVar(&reportPath, "report", "", "Write a JSON report of the run to this file, or - for stdout")
+	})
+	if ok == false {
+		return exitUsage
+	}
+
+	if snapshotPath != "" && dryRun == false {
+		return failSync(exitUsage, errors.New("--snapshot can only be used with --dry-run"))
+	}
+
+	image.SetDropboxDir(dropboxDir)
+
+	client, p, code, err := buildPlan()
+	if err != nil {
+		return failSync(code, err)
+	}
+	p.BatchSize = batchSize
+
+	if dryRun {
+		p.Print(os.Stdout)
+		return exitOK
+	}
+
+	report, err := p.Apply(client)
+	if err != nil {
+		return failSync(exitFailure, err)
+	}
+
+	if err := writeReport(report); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	if report.HasFailures() {
+		log.Printf("Sync finished with errors for %d cards", len(report.Errors))
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// failSync logs an error that stopped the sync, and reports it so the --report
+// is written on every run
+func failSync(code int, err error) int {
+	log.Println(err)
+	if err := writeReport(plan.FailedReport(err)); err != nil {
+		log.Println(err)
+	}
+
+	return code
+}
+
+// writeReport writes the report to --report, if it is set
+func writeReport(report *plan.Report) error {
+	if reportPath == "" {
+		return nil
+	}
+
+	return writeOutput(reportPath, report.Write)
+}
+
+// buildPlan compares the csv with the API, or with the --snapshot without a
+// Client. If the Plan couldn't be built the error is returned with the exit code to use
+func buildPlan() (*gql.Client, *plan.Plan, int, error) {
+	if prune != "" && prune != plan.PruneDelete && prune != plan.PruneDeactivatePreview {
+		return nil, nil, exitUsage, fmt.Errorf("Invalid --prune value: %s", prune)
+	}
+
+	var client *gql.Client
+	var snapshot *gql.Snapshot
+	var err error
+	if snapshotPath != "" {
+		snapshot, err = gql.ReadSnapshot(snapshotPath)
+		if err != nil {
+			return nil, nil, exitFailure, err
+		}
+		log.Printf("Comparing with the snapshot of %s from %s", snapshot.Endpoint, snapshot.CreatedAt.Format(time.RFC1123))
+	} else {
+		client, err = newClient()
+		if err != nil {
+			return nil, nil, exitUsage, err
+		}
+	}
+
+	var uploader upload.Uploader
+	if uploadTo != "" {
+		uploader, err = upload.Parse(uploadTo, uploadURL, uploadEndpoint)
+		if err != nil {
+			return nil, nil, exitUsage, err
+		}
+	}
+
+	cards, err := fetchCards()
+	if err != nil {
+		return nil, nil, exitFailure, err
+	}
+
+	for _, card := range cards {
+		if uploader != nil {
+			upload.SetURLs(uploader, card)
+		} else {
+			card.ImageVariants = image.Variants(card)
+		}
+	}
+
+	var gqlCards []*gql.Card
+	var lookups *gql.Lookups
+	if snapshot != nil {
+		gqlCards = snapshot.Cards
+		lookups = snapshot.Lookups()
+	} else {
+		// The lookups are used to create and update cards
+		if err := seedLookups(client); err != nil {
+			return nil, nil, exitFailure, err
+		}
+		if lookups, err = client.Lookups(); err != nil {
+			return nil, nil, exitFailure, err
+		}
+
+		gqlCards, err = client.FetchCards()
+		if err != nil {
+			return nil, nil, exitFailure, err
+		}
+	}
+
+	p := plan.Build(cards, gqlCards)
+	if baselinePath != "" {
+		baseline, err := merge.ReadBaseline(baselinePath)
+		if err != nil {
+			return nil, nil, exitFailure, err
+		}
+		p.Merge(baseline)
+	}
+	p.Resolve(lookups)
+	p.Prune = prune
+	p.Concurrency = concurrency
+	p.Uploader = uploader
+
+	return client, p, exitOK, nil
+}
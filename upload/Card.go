@@ -0,0 +1,48 @@
+package upload
+
+import (
+	"mxdb-tools/csv"
+	"mxdb-tools/image"
+	"strings"
+)
+
+// SetURLs points the card's image URLs at where the Uploader serves each
+// Rendition. Renditions and Encodings without a csv column become ImageVariants
+func SetURLs(u Uploader, card *csv.Card) {
+	variants := make(map[string]string)
+
+	for _, r := range image.Renditions() {
+		url := u.URL(r.Name + "/" + r.Filename(card))
+		if card.SetImageURL(r.Name, url) == false {
+			variants[r.Name+"."+strings.ToLower(r.Format)] = url
+		}
+
+		for _, encoding := range r.Encodings {
+			key := r.Name + "/" + r.EncodingFilename(card, encoding)
+			variants[r.Name+"."+strings.ToLower(encoding.Format)] = u.URL(key)
+		}
+	}
+
+	card.ImageVariants = nil
+	if len(variants) != 0 {
+		card.ImageVariants = variants
+	}
+}
+
+// Card uploads every Rendition of the card
+func Card(u Uploader, card *csv.Card) error {
+	for _, r := range image.Renditions() {
+		if err := u.Upload(r.Name+"/"+r.Filename(card), r.Path(card)); err != nil {
+			return err
+		}
+
+		for _, encoding := range r.Encodings {
+			key := r.Name + "/" + r.EncodingFilename(card, encoding)
+			if err := u.Upload(key, r.EncodingPath(card, encoding)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
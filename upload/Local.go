@@ -0,0 +1,44 @@
+package upload
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Local copies files into a directory, e.g. one served by a web server or used in tests
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+// URL is the BaseURL joined with the key, or a file:// URL without a BaseURL
+func (local *Local) URL(key string) string {
+	if local.BaseURL == "" {
+		path, _ := filepath.Abs(filepath.Join(local.Dir, filepath.FromSlash(key)))
+		return "file://" + filepath.ToSlash(path)
+	}
+
+	return joinURL(local.BaseURL, key)
+}
+
+// Upload copies the file into the directory
+func (local *Local) Upload(key string, path string) error {
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	dest := filepath.Join(local.Dir, filepath.FromSlash(key))
+
+	if existing, err := ioutil.ReadFile(dest); err == nil && bytes.Equal(existing, body) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(dest, body, 0644)
+}
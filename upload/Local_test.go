@@ -0,0 +1,168 @@
+package upload
+
+import (
+	"io/ioutil"
+	"mxdb-tools/csv"
+	"mxdb-tools/image"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// useRenditions switches to a large jpeg with a webp encoding and a png
+// preview, saved under a temporary directory
+func useRenditions(t *testing.T) string {
+	dir := t.TempDir()
+	image.SetBaseDir(dir)
+
+	previous := image.Renditions()
+	image.SetRenditions([]*image.Rendition{
+		{Name: "large", Source: image.Original, Height: 100, Format: "jpeg", Encodings: []image.Encoding{{Format: "webp"}}},
+		{Name: "preview", Source: "large", Height: 50, Format: "png"},
+	})
+	t.Cleanup(func() {
+		image.SetRenditions(previous)
+	})
+
+	return dir
+}
+
+func TestParse(t *testing.T) {
+	uploader, err := Parse("s3://cards/images/v1/", "https://cdn.example.com", "s3.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3, ok := uploader.(*S3)
+	if ok == false || s3.Bucket != "cards" || s3.Prefix != "images/v1" {
+		t.Fatalf("Parse() = %#v, want the cards bucket with the images/v1 prefix", uploader)
+	}
+	if url := s3.URL("large/A-001.jpg"); url != "https://cdn.example.com/images/v1/large/A-001.jpg" {
+		t.Errorf("URL() = %s", url)
+	}
+
+	uploader, err = Parse("s3://cards", "", "s3.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url := uploader.URL("large/A-001.jpg"); url != "https://s3.example.com/cards/large/A-001.jpg" {
+		t.Errorf("URL() = %s", url)
+	}
+
+	uploader, err = Parse("public/images", "https://example.com/images/", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(uploader, &Local{Dir: "public/images", BaseURL: "https://example.com/images/"}) == false {
+		t.Errorf("Parse() = %#v, want a Local directory", uploader)
+	}
+}
+
+func TestLocalURL(t *testing.T) {
+	local := &Local{Dir: "images", BaseURL: "https://example.com/images/"}
+	if url := local.URL("large/A-001.jpg"); url != "https://example.com/images/large/A-001.jpg" {
+		t.Errorf("URL() = %s", url)
+	}
+
+	local.BaseURL = ""
+	abs, _ := filepath.Abs(filepath.Join("images", "large", "A-001.jpg"))
+	if url := local.URL("large/A-001.jpg"); url != "file://"+filepath.ToSlash(abs) {
+		t.Errorf("URL() = %s, want a file URL", url)
+	}
+}
+
+func TestLocalUpload(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "A-001.jpg")
+	if err := ioutil.WriteFile(src, []byte("jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	local := &Local{Dir: t.TempDir()}
+	dest := filepath.Join(local.Dir, "large", "A-001.jpg")
+	if err := local.Upload("large/A-001.jpg", src); err != nil {
+		t.Fatal(err)
+	}
+	if body, err := ioutil.ReadFile(dest); err != nil || string(body) != "jpeg" {
+		t.Fatalf("Upload() copied %q, %v", body, err)
+	}
+
+	// An unchanged file isn't written again
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(dest, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.Upload("large/A-001.jpg", src); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(dest); err != nil || info.ModTime().Equal(old) == false {
+		t.Error("Upload() rewrote an unchanged file")
+	}
+
+	if err := ioutil.WriteFile(src, []byte("new jpeg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.Upload("large/A-001.jpg", src); err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := ioutil.ReadFile(dest); string(body) != "new jpeg" {
+		t.Errorf("Upload() didn't replace a changed file, it has %q", body)
+	}
+
+	if err := local.Upload("large/A-002.jpg", filepath.Join(local.Dir, "missing.jpg")); err == nil {
+		t.Error("Upload() succeeded without a file")
+	}
+}
+
+func TestSetURLs(t *testing.T) {
+	useRenditions(t)
+
+	card := &csv.Card{UID: "A-001", ImageVariants: map[string]string{"old.webp": "https://example.com/old.webp"}}
+	SetURLs(&Local{Dir: "images", BaseURL: "https://example.com"}, card)
+
+	if card.LargeImageURL != "https://example.com/large/A-001.jpg" {
+		t.Errorf("LargeImageURL = %s", card.LargeImageURL)
+	}
+
+	want := map[string]string{
+		"large.webp":  "https://example.com/large/A-001.webp",
+		"preview.png": "https://example.com/preview/A-001.png",
+	}
+	if reflect.DeepEqual(card.ImageVariants, want) == false {
+		t.Errorf("ImageVariants = %v, want %v", card.ImageVariants, want)
+	}
+}
+
+func TestCard(t *testing.T) {
+	dir := useRenditions(t)
+
+	card := &csv.Card{UID: "A-001"}
+	files := []string{"large/A-001.jpg", "large/A-001.webp", "preview/A-001.png"}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	local := &Local{Dir: t.TempDir()}
+	if err := Card(local, card); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		if body, err := ioutil.ReadFile(filepath.Join(local.Dir, filepath.FromSlash(file))); err != nil || string(body) != file {
+			t.Errorf("Card() uploaded %s as %q, %v", file, body, err)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, "preview", "A-001.png")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Card(local, card); err == nil {
+		t.Error("Card() succeeded with a rendition missing")
+	}
+}
@@ -0,0 +1,98 @@
+package upload
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 uploads files to an S3 compatible bucket. Credentials are read from
+// the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables
+type S3 struct {
+	Bucket  string
+	Prefix  string
+	BaseURL string
+
+	client *minio.Client
+}
+
+// NewS3 connects to the bucket at the endpoint, e.g. s3.amazonaws.com
+func NewS3(endpoint string, bucket string, prefix string, baseURL string) (*S3, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewEnvAWS(),
+		Secure: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if baseURL == "" {
+		baseURL = "https://" + endpoint + "/" + bucket
+	}
+
+	return &S3{
+		Bucket:  bucket,
+		Prefix:  prefix,
+		BaseURL: baseURL,
+		client:  client,
+	}, nil
+}
+
+// URL is the BaseURL joined with the prefixed key
+func (s3 *S3) URL(key string) string {
+	return joinURL(s3.BaseURL, s3.objectName(key))
+}
+
+// Upload puts the file in the bucket unless an object with the same checksum is already there
+func (s3 *S3) Upload(key string, filePath string) error {
+	ctx := context.Background()
+	objectName := s3.objectName(key)
+
+	checksum, err := md5File(filePath)
+	if err != nil {
+		return err
+	}
+
+	info, err := s3.client.StatObject(ctx, s3.Bucket, objectName, minio.StatObjectOptions{})
+	if err == nil && strings.Trim(info.ETag, `"`) == checksum {
+		return nil
+	}
+
+	_, err = s3.client.FPutObject(ctx, s3.Bucket, objectName, filePath, minio.PutObjectOptions{
+		ContentType: mime.TypeByExtension(path.Ext(filePath)),
+	})
+
+	return err
+}
+
+func (s3 *S3) objectName(key string) string {
+	if s3.Prefix == "" {
+		return key
+	}
+
+	return s3.Prefix + "/" + key
+}
+
+func md5File(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
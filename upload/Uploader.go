@@ -0,0 +1,34 @@
+package upload
+
+import (
+	"strings"
+)
+
+// Uploader publishes image files and knows the public URL they are served from
+type Uploader interface {
+	// URL is where the file uploaded as key is served from
+	URL(key string) string
+	// Upload publishes the file at path as key, skipping it if it is unchanged
+	Upload(key string, path string) error
+}
+
+// Parse turns a flag value into an Uploader: s3://bucket/prefix for S3,
+// anything else is a local directory. baseURL is the public URL of the files
+func Parse(s string, baseURL string, endpoint string) (Uploader, error) {
+	if strings.HasPrefix(s, "s3://") {
+		bucket := strings.TrimPrefix(s, "s3://")
+		prefix := ""
+		if i := strings.Index(bucket, "/"); i >= 0 {
+			prefix = strings.Trim(bucket[i+1:], "/")
+			bucket = bucket[:i]
+		}
+		return NewS3(endpoint, bucket, prefix, baseURL)
+	}
+
+	return &Local{Dir: s, BaseURL: baseURL}, nil
+}
+
+// joinURL appends a key to a base URL
+func joinURL(baseURL string, key string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
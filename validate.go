@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"mxdb-tools/validate"
+	"os"
+)
+
+var skipURLs bool
+
+// runValidate lints every row of the csv, failing if there are any problems
+func runValidate(args []string) int {
+	ok := parseFlags("validate", args, func(flags *flag.FlagSet) {
+		flags.BoolVar(&skipURLs, "skip-urls", false, "Don't check that image URLs are reachable")
+	})
+	if ok == false {
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		log.Println(err)
+		return exitUsage
+	}
+
+	cards, err := fetchCards()
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	if err := seedLookups(client); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	schema, err := validate.FetchSchema(client)
+	if err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	report := validate.Cards(cards, schema)
+	if skipURLs == false {
+		validate.URLs(report, cards, concurrency)
+	}
+
+	report.Print(os.Stdout)
+
+	if report.HasProblems() {
+		return exitFailure
+	}
+
+	return exitOK
+}
@@ -0,0 +1,125 @@
+package validate
+
+import (
+	"mxdb-tools/csv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var uidFormat = regexp.MustCompile(`^[A-Za-z0-9]+([-_][A-Za-z0-9]+)*$`)
+var uidNumber = regexp.MustCompile(`([0-9]+)[A-Za-z]*$`)
+
+// Cards checks every row of the csv against the Schema
+func Cards(cards []*csv.Card, schema *Schema) *Report {
+	report := &Report{}
+
+	rows := make(map[string]int)
+	for i, card := range cards {
+		if first, ok := rows[card.UID]; ok && card.UID != "" {
+			report.add(i, card.UID, "uid", "duplicate of row %d", first+2)
+		} else {
+			rows[card.UID] = i
+		}
+
+		checkCard(report, i, card, schema)
+	}
+
+	return report
+}
+
+func checkCard(report *Report, i int, card *csv.Card, schema *Schema) {
+	required := map[string]string{
+		"uid":                card.UID,
+		"rarity":             card.Rarity,
+		"set":                card.Set,
+		"title":              card.Title,
+		"type":               card.Type,
+		"symbol":             card.Symbol,
+		"original_image_url": card.OriginalImageURL,
+	}
+	if card.Type == "Character" {
+		required["subtitle"] = card.Subtitle
+		required["trait"] = strings.Join(card.Traits, csv.ListSeparator)
+	}
+	for _, field := range sortedKeys(required) {
+		if required[field] == "" {
+			report.add(i, card.UID, field, "required for %s cards", card.Type)
+		}
+	}
+
+	if card.UID != "" {
+		if uidFormat.MatchString(card.UID) == false {
+			report.add(i, card.UID, "uid", "%q is not a valid UID", card.UID)
+		} else if match := uidNumber.FindStringSubmatch(card.UID); match == nil {
+			report.add(i, card.UID, "uid", "doesn't end in the card number %d", card.Number)
+		} else if number, _ := strconv.Atoi(match[1]); number != card.Number {
+			report.add(i, card.UID, "number", "%d doesn't match the UID", card.Number)
+		}
+	}
+
+	if card.Number <= 0 {
+		report.add(i, card.UID, "number", "must be greater than 0")
+	}
+
+	if card.Rarity != "" && contains(schema.Rarities, card.Rarity) == false {
+		report.add(i, card.UID, "rarity", "unknown rarity %q", card.Rarity)
+	}
+	if card.Set != "" && contains(schema.Sets, card.Set) == false {
+		report.add(i, card.UID, "set", "unknown set %q", card.Set)
+	}
+	for j, effect := range card.Effects() {
+		symbol, text := effectColumns(j)
+		if effect.IsEmpty() && j != 0 {
+			report.add(i, card.UID, text, "blank, but a later effect is filled in")
+		} else if effect.Symbol == "" && j != 0 {
+			report.add(i, card.UID, symbol, "required when there is an %s", text)
+		}
+		if effect.Symbol != "" && contains(schema.Symbols, effect.Symbol) == false {
+			report.add(i, card.UID, symbol, "unknown symbol %q", effect.Symbol)
+		}
+	}
+	if card.Type != "" && contains(schema.Types, card.Type) == false {
+		report.add(i, card.UID, "type", "unknown type %q", card.Type)
+	}
+
+	traits := make(map[string]bool)
+	for _, trait := range card.Traits {
+		if traits[trait] {
+			report.add(i, card.UID, "trait", "%q is listed more than once", trait)
+		} else if schema.Lookups.Traits[trait] == "" {
+			report.add(i, card.UID, "trait", "unknown trait %q", trait)
+		}
+		traits[trait] = true
+	}
+
+	if err := card.CheckStats(); err != nil {
+		report.add(i, card.UID, "stats", "%s", err)
+	}
+	for _, name := range csv.StatNames {
+		if err := card.CheckStat(name); err != nil {
+			report.add(i, card.UID, name, "%s", err)
+			continue
+		}
+		if stat := card.Stat(name); stat.Valid && schema.Lookups.StatID(name, stat.Rank) == "" {
+			report.add(i, card.UID, name, "no %s stat with rank %d", name, stat.Rank)
+		}
+	}
+
+	if card.Previewer != "" && card.PreviewURL == "" {
+		report.add(i, card.UID, "preview_url", "required when there is a previewer")
+	}
+	if card.PreviewURL != "" && card.Previewer == "" {
+		report.add(i, card.UID, "previewer", "required when there is a preview_url")
+	}
+}
+
+// effectColumns returns the names of the i-th pair of effect columns
+func effectColumns(i int) (string, string) {
+	if i == 0 {
+		return "symbol", "effect"
+	}
+
+	suffix := "_" + strconv.Itoa(i+1)
+	return "symbol" + suffix, "effect" + suffix
+}
@@ -0,0 +1,172 @@
+package validate
+
+import (
+	"bytes"
+	"mxdb-tools/csv"
+	"mxdb-tools/gql"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func testSchema() *Schema {
+	return &Schema{
+		Rarities: []string{"C", "R"},
+		Sets:     []string{"AA"},
+		Symbols:  []string{"Constant", "Push"},
+		Types:    []string{"Character", "Event", "Battle"},
+		Lookups: &gql.Lookups{
+			Strength:     map[int]string{1: "s1"},
+			Intelligence: map[int]string{1: "i1"},
+			Special:      map[int]string{1: "p1"},
+			Traits:       map[string]string{"Hero": "t1", "Flying": "t2"},
+		},
+	}
+}
+
+func validCard() *csv.Card {
+	card := &csv.Card{
+		UID:              "AA-001",
+		Rarity:           "C",
+		Number:           1,
+		Set:              "AA",
+		Title:            "Hero",
+		Subtitle:         "The First",
+		Type:             "Character",
+		Traits:           []string{"Hero", "Flying"},
+		Symbol:           "Constant",
+		Effect:           "Draw a card.",
+		OriginalImageURL: "https://example.com/AA-001.jpg",
+	}
+	for _, name := range csv.StatNames {
+		card.SetStat(name, csv.NewStat(1))
+	}
+
+	return card
+}
+
+// fields lists the field of every Problem in the Report
+func fields(report *Report) []string {
+	var fields []string
+	for _, problem := range report.Problems {
+		fields = append(fields, problem.Field)
+	}
+
+	return fields
+}
+
+func TestCards(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(card *csv.Card)
+		want   []string
+	}{
+		{"valid", func(card *csv.Card) {}, nil},
+		{"missing title", func(card *csv.Card) { card.Title = "" }, []string{"title"}},
+		{"character without subtitle or traits", func(card *csv.Card) {
+			card.Subtitle = ""
+			card.Traits = nil
+		}, []string{"subtitle", "trait"}},
+		{"invalid uid", func(card *csv.Card) { card.UID = "AA 001" }, []string{"uid"}},
+		{"uid without a number", func(card *csv.Card) { card.UID = "AA" }, []string{"uid"}},
+		{"number that doesn't match the uid", func(card *csv.Card) { card.Number = 2 }, []string{"number"}},
+		{"number 0", func(card *csv.Card) {
+			card.UID = "AA-000"
+			card.Number = 0
+		}, []string{"number"}},
+		{"unknown enums", func(card *csv.Card) {
+			card.Rarity = "X"
+			card.Set = "ZZ"
+			card.Symbol = "Pull"
+		}, []string{"rarity", "set", "symbol"}},
+		{"unknown type", func(card *csv.Card) { card.Type = "Place" }, []string{"type"}},
+		{"blank effect before a filled in one", func(card *csv.Card) {
+			card.Symbol3 = "Push"
+			card.Effect3 = "Move."
+		}, []string{"effect_2"}},
+		{"effect without a symbol", func(card *csv.Card) { card.Effect2 = "Move." }, []string{"symbol_2"}},
+		{"repeated trait", func(card *csv.Card) { card.Traits = []string{"Hero", "Hero"} }, []string{"trait"}},
+		{"unknown trait", func(card *csv.Card) { card.Traits = []string{"Villain"} }, []string{"trait"}},
+		{"missing stat", func(card *csv.Card) { card.SetStat("special", csv.Stat{}) }, []string{"special"}},
+		{"unknown stat rank", func(card *csv.Card) { card.SetStat("strength", csv.NewStat(7)) }, []string{"strength"}},
+		{"event with stats", func(card *csv.Card) {
+			card.Type = "Event"
+			card.SetStat("strength", csv.Stat{})
+			card.SetStat("intelligence", csv.Stat{})
+		}, []string{"special"}},
+		{"battle without stats", func(card *csv.Card) {
+			card.Type = "Battle"
+			for _, name := range csv.StatNames {
+				card.SetStat(name, csv.Stat{})
+			}
+		}, []string{"stats"}},
+		{"previewer without a url", func(card *csv.Card) { card.Previewer = "Someone" }, []string{"preview_url"}},
+		{"preview url without a previewer", func(card *csv.Card) { card.PreviewURL = "https://example.com" }, []string{"previewer"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			card := validCard()
+			test.change(card)
+
+			report := Cards([]*csv.Card{card}, testSchema())
+			if got := fields(report); reflect.DeepEqual(got, test.want) == false {
+				t.Errorf("Cards() found problems with %v, want %v: %+v", got, test.want, report.Problems)
+			}
+			if report.HasProblems() != (len(test.want) != 0) {
+				t.Errorf("HasProblems() = %t", report.HasProblems())
+			}
+		})
+	}
+}
+
+func TestCardsDuplicateUID(t *testing.T) {
+	report := Cards([]*csv.Card{validCard(), validCard()}, testSchema())
+
+	want := []Problem{{Row: 3, UID: "AA-001", Field: "uid", Message: "duplicate of row 2"}}
+	if reflect.DeepEqual(report.Problems, want) == false {
+		t.Errorf("Cards() = %+v, want %+v", report.Problems, want)
+	}
+}
+
+func TestReportPrint(t *testing.T) {
+	report := &Report{}
+	report.add(3, "AA-004", "title", "required for %s cards", "Character")
+	report.add(0, "AA-001", "set", "unknown set %q", "ZZ")
+
+	var buf bytes.Buffer
+	report.Print(&buf)
+
+	want := "row 2 (AA-001) set: unknown set \"ZZ\"\nrow 5 (AA-004) title: required for Character cards\n2 problems found\n"
+	if buf.String() != want {
+		t.Errorf("Print() wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/missing.jpg":
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/get-only.jpg" && r.Method == "HEAD":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	cards := []*csv.Card{
+		{UID: "AA-001", OriginalImageURL: server.URL + "/ok.jpg", LargeImageURL: server.URL + "/get-only.jpg"},
+		{UID: "AA-002", OriginalImageURL: server.URL + "/ok.jpg", ThumbnailImageURL: server.URL + "/missing.jpg"},
+	}
+
+	report := &Report{}
+	URLs(report, cards, 2)
+
+	if len(report.Problems) != 1 {
+		t.Fatalf("URLs() = %+v, want only the missing thumbnail", report.Problems)
+	}
+	if problem := report.Problems[0]; problem.Row != 3 || problem.Field != "thumbnail_image_url" {
+		t.Errorf("URLs() = %+v, want the thumbnail of row 3", problem)
+	}
+}
@@ -0,0 +1,49 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Problem is a single issue with a row of the csv
+type Problem struct {
+	Row     int
+	UID     string
+	Field   string
+	Message string
+}
+
+// Report collects the Problems found in the csv
+type Report struct {
+	Problems []Problem
+}
+
+// add records a Problem for the card at index i of the csv. The header is
+// row 1, so the first card is row 2
+func (report *Report) add(i int, uid string, field string, format string, args ...interface{}) {
+	report.Problems = append(report.Problems, Problem{
+		Row:     i + 2,
+		UID:     uid,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// HasProblems returns true if anything failed validation
+func (report *Report) HasProblems() bool {
+	return len(report.Problems) != 0
+}
+
+// Print writes every Problem ordered by row
+func (report *Report) Print(w io.Writer) {
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		return report.Problems[i].Row < report.Problems[j].Row
+	})
+
+	for _, problem := range report.Problems {
+		fmt.Fprintf(w, "row %d (%s) %s: %s\n", problem.Row, problem.UID, problem.Field, problem.Message)
+	}
+
+	fmt.Fprintf(w, "%d problems found\n", len(report.Problems))
+}
@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"mxdb-tools/gql"
+)
+
+// Schema is what the API accepts for each csv column
+type Schema struct {
+	Rarities []string
+	Sets     []string
+	Symbols  []string
+	Types    []string
+	Lookups  *gql.Lookups
+}
+
+// FetchSchema loads the enums, traits and stat ranks from the API
+func FetchSchema(client *gql.Client) (*Schema, error) {
+	var err error
+	schema := &Schema{}
+
+	if schema.Rarities, err = client.FetchEnumValues("CardRarity"); err != nil {
+		return nil, err
+	}
+	if schema.Sets, err = client.FetchEnumValues("CardSet"); err != nil {
+		return nil, err
+	}
+	if schema.Symbols, err = client.FetchEnumValues("CardSymbol"); err != nil {
+		return nil, err
+	}
+	if schema.Types, err = client.FetchEnumValues("CardType"); err != nil {
+		return nil, err
+	}
+	if schema.Lookups, err = client.Lookups(); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package validate
+
+import (
+	"fmt"
+	"mxdb-tools/csv"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+var urlClient = &http.Client{Timeout: 30 * time.Second}
+
+// URLs checks that every image URL in the csv can be reached
+func URLs(report *Report, cards []*csv.Card, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, card := range cards {
+		urls := map[string]string{
+			"original_image_url":  card.OriginalImageURL,
+			"large_image_url":     card.LargeImageURL,
+			"medium_image_url":    card.MediumImageURL,
+			"small_image_url":     card.SmallImageURL,
+			"thumbnail_image_url": card.ThumbnailImageURL,
+		}
+
+		for _, field := range sortedKeys(urls) {
+			if urls[field] == "" {
+				continue
+			}
+
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, uid string, field string, url string) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				if err := checkURL(url); err != nil {
+					mu.Lock()
+					report.add(i, uid, field, "%s", err)
+					mu.Unlock()
+				}
+			}(i, card.UID, field, urls[field])
+		}
+	}
+
+	wg.Wait()
+}
+
+// checkURL makes a HEAD request, falling back to GET for servers that don't support HEAD
+func checkURL(url string) error {
+	resp, err := urlClient.Head(url)
+	if err == nil && resp.StatusCode == http.StatusMethodNotAllowed {
+		resp.Body.Close()
+		resp, err = urlClient.Get(url)
+	}
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}